@@ -0,0 +1,278 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/iawia002/lux/config"
+	"github.com/iawia002/lux/downloader"
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
+	"github.com/iawia002/lux/server"
+	"github.com/iawia002/lux/watch"
+)
+
+// extractorsCommand lists the registered extractors.
+func extractorsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "extractors",
+		Usage: "List the supported sites with their domains and capabilities",
+		Action: func(c *cli.Context) error {
+			w := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SITE\tDOMAINS\tCAPABILITIES") // nolint
+			for _, site := range extractors.Sites() {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", siteLabel(site.Name), siteDomains(site), capabilityList(site.Capabilities)) // nolint
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// whichCommand prints the extractor of the URL without extracting it.
+func whichCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "which",
+		Usage:     "Print the extractor and the normalized URL of the URL without extracting it",
+		ArgsUsage: "URL",
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("which needs exactly one URL")
+			}
+			if err := request.SetOptions(requestOptions(c, "")); err != nil {
+				return err
+			}
+			u, chain, err := extractors.Resolve(nil, c.Args().First())
+			if err != nil {
+				return err
+			}
+			m, err := extractors.Lookup(u)
+			if err != nil {
+				return err
+			}
+			if m.Extractor == nil {
+				return fmt.Errorf("no extractor of %s", u)
+			}
+
+			cyan := color.New(color.FgCyan)
+			out := c.App.Writer
+			cyan.Fprint(out, " Site:          ") // nolint
+			fmt.Fprintln(out, siteLabel(m.Site)) // nolint
+			if m.Site != "" {
+				cyan.Fprint(out, " Route:         ") // nolint
+				fmt.Fprintln(out, m.Route)           // nolint
+			}
+			cyan.Fprint(out, " URL:           ") // nolint
+			fmt.Fprintln(out, m.URL)             // nolint
+			if len(chain) > 0 {
+				cyan.Fprint(out, " Redirects:     ")                      // nolint
+				fmt.Fprintln(out, strings.Join(append(chain, u), " -> ")) // nolint
+			}
+			cyan.Fprint(out, " Capabilities:  ")              // nolint
+			fmt.Fprintln(out, capabilityList(m.Capabilities)) // nolint
+			return nil
+		},
+	}
+}
+
+// serveCommand runs the HTTP job API, the download flags are the defaults of the jobs.
+func serveCommand(conf *config.File) *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "Run an HTTP server that queues, downloads and serves the submitted URLs",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "addr",
+				Value: "127.0.0.1:8080",
+				Usage: "Address to listen on, the API has no authentication, so expose it to trusted networks only",
+			},
+			&cli.UintFlag{
+				Name:  "workers",
+				Value: 2,
+				Usage: "Number of jobs that run at the same time",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			source, saveCookies, err := setupRequest(c)
+			if err != nil {
+				return err
+			}
+			defer saveCookies() // nolint
+
+			// the flags are checked before the server starts
+			if _, _, err = options(c, conf.Sites, "", source); err != nil {
+				return err
+			}
+			s := server.New(server.Options{
+				Workers:    int(c.Uint("workers")),
+				OutputPath: c.String("output-path"),
+				SiteOptions: func(url string) (extractors.Options, downloader.Options, error) {
+					return options(c, conf.Sites, url, source)
+				},
+			})
+			httpServer := &http.Server{
+				Addr:              c.String("addr"),
+				Handler:           s.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- httpServer.ListenAndServe()
+			}()
+			fmt.Fprintf(color.Output, "Serving on %s\n", color.CyanString("http://%s", httpServer.Addr)) // nolint
+			select {
+			case err = <-errCh:
+			case <-ctx.Done():
+			}
+			// the running jobs are paused, and the event streams are closed before the server shuts down
+			s.Close()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx) // nolint
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+// watchCommand downloads the new items of the subscriptions until it is interrupted.
+func watchCommand(conf *config.File) *cli.Command {
+	return &cli.Command{
+		Name:      "watch",
+		Usage:     "Download the new items of the subscribed channels and playlists periodically",
+		ArgsUsage: "[SUBSCRIPTIONS_FILE]",
+		Description: "The subscriptions file defaults to $XDG_CONFIG_HOME/lux/subscriptions.toml, the downloaded items are " +
+			"recorded in the archive, so they are downloaded only once.",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Interval of the subscriptions that don't set their own (default: the interval in the file, or 1h)",
+			},
+			&cli.StringFlag{
+				Name:  "archive",
+				Usage: "Path of the archive of the downloaded items (default: archive.txt next to the subscriptions file)",
+			},
+			&cli.BoolFlag{
+				Name:  "once",
+				Usage: "Check every subscription once and exit",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			path := c.Args().First()
+			if path == "" {
+				path = watch.DefaultPath()
+			}
+			file, err := watch.Load(path)
+			if err != nil {
+				return err
+			}
+			archivePath := c.String("archive")
+			if archivePath == "" {
+				archivePath = filepath.Join(filepath.Dir(path), "archive.txt")
+			}
+			archive, err := watch.OpenArchive(archivePath)
+			if err != nil {
+				return err
+			}
+
+			source, saveCookies, err := setupRequest(c)
+			if err != nil {
+				return err
+			}
+			defer saveCookies() // nolint
+
+			interval := file.Interval
+			if c.IsSet("interval") {
+				interval = c.Duration("interval")
+			}
+			if _, _, err = options(c, conf.Sites, "", source); err != nil {
+				return err
+			}
+			w := watch.New(file.Subscriptions, archive, watch.Options{
+				Interval: interval,
+				SiteOptions: func(url string) (extractors.Options, downloader.Options, error) {
+					return options(c, conf.Sites, url, source)
+				},
+			})
+
+			// the running download stops on the signal, its partial files are kept to resume it next time
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if !c.Bool("once") {
+				return w.Run(ctx)
+			}
+			var failed bool
+			for i := range file.Subscriptions {
+				s := &file.Subscriptions[i]
+				if _, err = w.Check(ctx, s); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					fmt.Fprintf(color.Output, "Checking %s error:\n%+v\n", color.CyanString("%s", s), err) // nolint
+					failed = true
+				}
+			}
+			if failed {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// siteLabel returns the name of the site, the universal extractor has no name.
+func siteLabel(name string) string {
+	if name == "" {
+		return "universal"
+	}
+	return name
+}
+
+// siteDomains describes the URLs that the site extracts.
+func siteDomains(site extractors.Site) string {
+	if site.Name == "" {
+		return "any URL that no other site matches"
+	}
+	var domains []string
+	for _, r := range site.Routes {
+		switch {
+		case r.Host == "" && r.Pattern == nil:
+			domains = append(domains, r.Domain+".*")
+		case r.Pattern == nil:
+			domains = append(domains, r.Host)
+		default:
+			domains = append(domains, r.String())
+		}
+	}
+	for _, id := range site.ShortIDs {
+		domains = append(domains, "id:"+id.Pattern.String())
+	}
+	return strings.Join(domains, ", ")
+}
+
+func capabilityList(caps []extractors.Capability) string {
+	if len(caps) == 0 {
+		return "-"
+	}
+	names := make([]string, len(caps))
+	for i, c := range caps {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/iawia002/lux/config"
+	"github.com/iawia002/lux/extractors"
+)
+
+// loadConfig reads the config file and uses its values as the defaults of the flags not set on the command line,
+// the site options set on the command line are cleared in the returned file, so the command line always wins.
+func loadConfig(c *cli.Context) (*config.File, error) {
+	if c.Bool("ignore-config") {
+		return &config.File{}, nil
+	}
+	path := c.String("config")
+	if path == "" {
+		path = config.DefaultPath()
+		if _, err := os.Stat(path); path == "" || os.IsNotExist(err) {
+			// the default config file is optional
+			return &config.File{}, nil
+		}
+	}
+	file, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// clear the site options set on the command line before the flags are set by the config file
+	for name, site := range file.Sites {
+		if c.IsSet("cookie") || c.IsSet("cookies-from-browser") {
+			site.Cookie = ""
+		}
+		if c.IsSet("proxy") {
+			site.Proxy = ""
+		}
+		if c.IsSet("stream-format") {
+			site.StreamFormat = ""
+		}
+		if c.IsSet("output-path") {
+			site.OutputPath = ""
+		}
+		if c.IsSet("output-name") {
+			site.OutputName = ""
+		}
+		file.Sites[name] = site
+	}
+	for key, value := range file.Flags {
+		name := flagName(c, key)
+		if name == "" || name == "config" || name == "ignore-config" {
+			return nil, fmt.Errorf("unknown option %s in config file %s", key, path)
+		}
+		if c.IsSet(name) {
+			continue
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			if err = c.Set(name, fmt.Sprint(v)); err != nil {
+				return nil, fmt.Errorf("invalid option %s in config file %s: %w", name, path, err)
+			}
+		}
+	}
+	return file, nil
+}
+
+// flagName returns the name of the flag of the name or alias, it is empty if there is no such flag.
+func flagName(c *cli.Context, name string) string {
+	for _, flag := range c.App.Flags {
+		names := flag.Names()
+		for _, n := range names {
+			if n == name {
+				return names[0]
+			}
+		}
+	}
+	return ""
+}
+
+// siteName returns the name of the site of the URL, eg: bilibili, which is the key of the site options in the config file.
+func siteName(videoURL string) string {
+	m, err := extractors.Lookup(videoURL)
+	if err != nil {
+		return ""
+	}
+	return m.Site
+}
+
+// readCookie returns the content of the cookie file if cookie is a file path.
+func readCookie(cookie string) (string, error) {
+	if cookie == "" {
+		return "", nil
+	}
+	if _, err := os.Stat(cookie); err != nil {
+		return cookie, nil
+	}
+	data, err := os.ReadFile(cookie)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// File is the content of the config file, eg:
+//
+//	output-path = "/data/videos"
+//	multi-thread = true
+//	thread = 16
+//
+//	[sites.bilibili]
+//	cookie = "/data/bilibili-cookies.txt"
+//	stream-format = "80-12"
+//	output-name = "{{.Title}}"
+//
+//	[[plugins]]
+//	name = "example"
+//	command = "/usr/local/bin/lux-example"
+//	domains = ["example"]
+type File struct {
+	// Flags are the default values of the command line flags, keyed by the flag name.
+	Flags map[string]interface{}
+	// Sites are the options of each site, keyed by the site name, eg: bilibili.
+	Sites map[string]Site
+	// Plugins are the external extractors.
+	Plugins []Plugin
+}
+
+// Site is the options of a site in the config file, they take precedence over the flags in the config file.
+type Site struct {
+	Cookie       string `toml:"cookie"`
+	Proxy        string `toml:"proxy"`
+	StreamFormat string `toml:"stream-format"`
+	OutputPath   string `toml:"output-path"`
+	// OutputName is the output file name, it can be a template of the extracted data, eg: "{{.Site}} {{.Title}}".
+	OutputName string `toml:"output-name"`
+}
+
+// Plugin is an external extractor, it is an executable that reads the URL and options in JSON from stdin
+// and writes the extracted data in JSON to stdout.
+type Plugin struct {
+	Name    string   `toml:"name"`
+	Command string   `toml:"command"`
+	Args    []string `toml:"args"`
+	// Domains are the hosts the plugin handles with their subdomains, eg: "example.io",
+	// or the site names, eg: "example" for example.com and example.net.
+	Domains []string `toml:"domains"`
+	// Patterns are the regular expressions of the URLs the plugin handles.
+	Patterns []string `toml:"patterns"`
+	// Timeout kills the plugin if it runs longer than it, eg: "5m", 0 means no timeout.
+	Timeout time.Duration `toml:"timeout"`
+}
+
+// DefaultPath returns the path of the config file, it is $XDG_CONFIG_HOME/lux/config.toml on Linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lux", "config.toml")
+}
+
+// Load reads the config file of the path.
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close() // nolint
+
+	file, err := Parse(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid config file %s", path)
+	}
+	return file, nil
+}
+
+// Parse parses the config file in TOML format.
+func Parse(r io.Reader) (*File, error) {
+	var raw map[string]toml.Primitive
+	md, err := toml.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	file := &File{
+		Flags: make(map[string]interface{}, len(raw)),
+		Sites: make(map[string]Site),
+	}
+	for key, value := range raw {
+		switch key {
+		case "sites":
+			if err = md.PrimitiveDecode(value, &file.Sites); err != nil {
+				return nil, errors.Wrap(err, "sites")
+			}
+			continue
+		case "plugins":
+			if err = md.PrimitiveDecode(value, &file.Plugins); err != nil {
+				return nil, errors.Wrap(err, "plugins")
+			}
+			continue
+		}
+		var v interface{}
+		if err = md.PrimitiveDecode(value, &v); err != nil {
+			return nil, errors.Wrap(err, key)
+		}
+		if _, ok := v.(map[string]interface{}); ok {
+			return nil, errors.Errorf("unknown section %s", key)
+		}
+		file.Flags[key] = v
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return nil, errors.Errorf("unknown options: %s", strings.Join(keys, ", "))
+	}
+	return file, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *File
+		wantErr bool
+	}{
+		{
+			name: "normal test",
+			content: `
+output-path = "/tmp"
+multi-thread = true
+thread = 16
+proxy-rules = ["iqiyi=http://127.0.0.1:1087"]
+
+[sites.bilibili]
+cookie = "SESSDATA=abc"
+stream-format = "80-12"
+output-name = "{{.Title}}"
+
+[sites.youtube]
+proxy = "socks5://127.0.0.1:1080"
+
+[[plugins]]
+name = "example"
+command = "/usr/local/bin/lux-example"
+args = ["-v"]
+domains = ["example"]
+patterns = ['^https?://v\.example\.io/']
+timeout = "5m"
+`,
+			want: &File{
+				Flags: map[string]interface{}{
+					"output-path":  "/tmp",
+					"multi-thread": true,
+					"thread":       int64(16),
+					"proxy-rules":  []interface{}{"iqiyi=http://127.0.0.1:1087"},
+				},
+				Sites: map[string]Site{
+					"bilibili": {Cookie: "SESSDATA=abc", StreamFormat: "80-12", OutputName: "{{.Title}}"},
+					"youtube":  {Proxy: "socks5://127.0.0.1:1080"},
+				},
+				Plugins: []Plugin{
+					{
+						Name:     "example",
+						Command:  "/usr/local/bin/lux-example",
+						Args:     []string{"-v"},
+						Domains:  []string{"example"},
+						Patterns: []string{`^https?://v\.example\.io/`},
+						Timeout:  5 * time.Minute,
+					},
+				},
+			},
+		},
+		{
+			name:    "unknown site option",
+			content: "[sites.bilibili]\nthread = 16\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown section",
+			content: "[bilibili]\ncookie = \"a=b\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown plugin option",
+			content: "[[plugins]]\nname = \"example\"\nurl = \"https://example.com\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid file",
+			content: "output-path = ",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
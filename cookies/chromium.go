@@ -0,0 +1,194 @@
+package cookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// chromiumBrowser reads cookies from the profiles of Chromium based browsers.
+type chromiumBrowser struct {
+	// configDir is the directory name under $XDG_CONFIG_HOME
+	configDir string
+	// keyringApplication is the application name used to store the password in the keyring
+	keyringApplication string
+}
+
+// keyringPassword looks up the "Safe Storage" password in the keyring,
+// it is a variable so that it can be replaced in tests.
+var keyringPassword = func(application string) string {
+	out, err := exec.Command("secret-tool", "lookup", "application", application).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (b chromiumBrowser) profileDir(profile string) (string, error) {
+	if isDir(profile) {
+		return profile, nil
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	if profile == "" {
+		profile = "Default"
+	}
+	dir := filepath.Join(configHome, b.configDir, profile)
+	if !isDir(dir) {
+		return "", errors.Errorf("%s profile %q not found", b.configDir, profile)
+	}
+	return dir, nil
+}
+
+func (b chromiumBrowser) cookies(profile string) ([]*http.Cookie, error) {
+	dir, err := b.profileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	// newer versions keep the cookie database in the Network directory
+	path := filepath.Join(dir, "Network", "Cookies")
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(dir, "Cookies")
+	}
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// since database version 24 the encrypted value is prefixed with the SHA256 hash of the host
+	var hostPrefixed bool
+	if meta, err := db.table("meta"); err == nil {
+		for _, row := range meta {
+			if row.String("key") == "version" {
+				version, _ := strconv.Atoi(row.String("value"))
+				hostPrefixed = version >= 24
+			}
+		}
+	}
+
+	rows, err := db.table("cookies")
+	if err != nil {
+		return nil, err
+	}
+	d := newChromiumDecryptor(keyringPassword(b.keyringApplication))
+	cookies := make([]*http.Cookie, 0, len(rows))
+	for _, row := range rows {
+		value := row.String("value")
+		if encrypted := row.Bytes("encrypted_value"); value == "" && len(encrypted) > 0 {
+			decrypted, err := d.decrypt(encrypted)
+			if err != nil {
+				// skip the cookies that can not be decrypted rather than failing completely
+				continue
+			}
+			if hostPrefixed && len(decrypted) >= 32 {
+				decrypted = decrypted[32:]
+			}
+			value = string(decrypted)
+		}
+		cookies = append(cookies, &http.Cookie{
+			Domain:   row.String("host_key"),
+			Name:     row.String("name"),
+			Value:    value,
+			Path:     row.String("path"),
+			Expires:  chromiumTime(row.Int("expires_utc")),
+			Secure:   row.Int("is_secure") == 1,
+			HttpOnly: row.Int("is_httponly") == 1,
+		})
+	}
+	return cookies, nil
+}
+
+// chromiumTime converts the microseconds since 1601-01-01 to time.Time, 0 means a session cookie.
+func chromiumTime(t int64) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	const epochOffset = 11644473600 // seconds between 1601-01-01 and 1970-01-01
+	return time.Unix(t/1000000-epochOffset, t%1000000*1000)
+}
+
+// chromiumDecryptor decrypts cookie values on Linux.
+// v10 values use the hard-coded password "peanuts",
+// v11 values use the password stored in the keyring, or an empty password if there is no keyring.
+type chromiumDecryptor struct {
+	v10Key   []byte
+	v11Keys  [][]byte
+	emptyKey []byte
+}
+
+func newChromiumDecryptor(password string) *chromiumDecryptor {
+	d := &chromiumDecryptor{
+		v10Key:   chromiumKey("peanuts"),
+		emptyKey: chromiumKey(""),
+	}
+	if password != "" {
+		d.v11Keys = append(d.v11Keys, chromiumKey(password))
+	}
+	d.v11Keys = append(d.v11Keys, d.emptyKey)
+	return d
+}
+
+func chromiumKey(password string) []byte {
+	key, _ := pbkdf2.Key(sha1.New, password, []byte("saltysalt"), 1, 16)
+	return key
+}
+
+func (d *chromiumDecryptor) decrypt(value []byte) ([]byte, error) {
+	var keys [][]byte
+	switch {
+	case bytes.HasPrefix(value, []byte("v10")):
+		keys = [][]byte{d.v10Key, d.emptyKey}
+	case bytes.HasPrefix(value, []byte("v11")):
+		keys = d.v11Keys
+	default:
+		return nil, errors.New("unknown cookie encryption version")
+	}
+	for _, key := range keys {
+		if plaintext, err := decryptAES128CBC(key, value[3:]); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, errors.New("failed to decrypt cookie value")
+}
+
+func decryptAES128CBC(key, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid ciphertext length")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	plaintext := make([]byte, len(ciphertext))
+	iv := bytes.Repeat([]byte{' '}, aes.BlockSize)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// remove PKCS#7 padding, invalid padding means the key is wrong
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("invalid padding")
+		}
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -0,0 +1,81 @@
+package cookies
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type browser interface {
+	cookies(profile string) ([]*http.Cookie, error)
+}
+
+var browsers = map[string]browser{
+	"firefox":  firefoxBrowser{},
+	"chromium": chromiumBrowser{configDir: "chromium", keyringApplication: "chromium"},
+	"chrome":   chromiumBrowser{configDir: "google-chrome", keyringApplication: "chrome"},
+}
+
+// SupportedBrowsers returns the names of the supported browsers.
+func SupportedBrowsers() []string {
+	names := make([]string, 0, len(browsers))
+	for name := range browsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromBrowser loads all cookies of the browser profile described by spec,
+// which has the form "browser[:profile]", eg: "firefox", "chromium:Profile 1".
+// The profile can be either a profile name or the path of a profile directory.
+func FromBrowser(spec string) ([]*http.Cookie, error) {
+	if runtime.GOOS != "linux" {
+		return nil, errors.Errorf("loading cookies from browsers is not supported on %s", runtime.GOOS)
+	}
+	name, profile, _ := strings.Cut(spec, ":")
+	b, ok := browsers[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.Errorf("unsupported browser %q, supported browsers: %s", name, strings.Join(SupportedBrowsers(), ", "))
+	}
+	return b.cookies(profile)
+}
+
+// Filter returns the unexpired cookies that would be sent to the given host.
+func Filter(cookies []*http.Cookie, host string) []*http.Cookie {
+	host = strings.ToLower(host)
+	now := time.Now()
+	filtered := make([]*http.Cookie, 0)
+	for _, c := range cookies {
+		if !c.Expires.IsZero() && c.Expires.Before(now) {
+			continue
+		}
+		domain := strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// Header formats the cookies as the value of a Cookie header, eg: "a=b; c=d".
+func Header(cookies []*http.Cookie) string {
+	pairs := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+func isDir(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,166 @@
+package cookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setHome(t *testing.T) string {
+	home, err := filepath.Abs(filepath.Join("testdata", "home"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	// make sure the default-release profile is the most recently used one
+	old := time.Date(2003, 3, 3, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(home, ".mozilla", "firefox", "efgh5678.dev", "cookies.sqlite"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+func cookieMap(cookies []*http.Cookie) map[string]string {
+	m := make(map[string]string, len(cookies))
+	for _, c := range cookies {
+		m[c.Name] = c.Value
+	}
+	return m
+}
+
+func TestFromBrowser(t *testing.T) {
+	home := setHome(t)
+	keyringPassword = func(string) string { return "" }
+
+	tests := []struct {
+		name  string
+		spec  string
+		host  string
+		want  map[string]string
+		count int
+	}{
+		{
+			name: "chromium default profile",
+			spec: "chromium",
+			host: "www.bilibili.com",
+			want: map[string]string{
+				"SESSDATA": "sessdata-value",
+				"buvid3":   "buvid-value",
+				"bili_jct": "plain-value",
+			},
+		},
+		{
+			name:  "chromium overflow pages",
+			spec:  "chromium:Default",
+			host:  "example.com",
+			want:  map[string]string{"long": strings.Repeat("x", 3000), "filler199": "v199"},
+			count: 201,
+		},
+		{
+			name: "chromium expired cookies",
+			spec: "chromium",
+			host: "www.youtube.com",
+			want: map[string]string{},
+		},
+		{
+			name: "firefox most recently used profile with WAL",
+			spec: "firefox",
+			host: "www.youtube.com",
+			want: map[string]string{
+				"VISITOR_INFO1_LIVE": "visitor",
+				"SID":                "sid-value",
+			},
+		},
+		{
+			name: "firefox expiry in milliseconds",
+			spec: "firefox",
+			host: "www.douyin.com",
+			want: map[string]string{"ttwid": "ttwid-value"},
+		},
+		{
+			name: "firefox profile name",
+			spec: "firefox:dev",
+			host: "m.youtube.com",
+			want: map[string]string{"dev": "dev-value"},
+		},
+		{
+			name: "firefox profile path",
+			spec: "firefox:" + filepath.Join(home, ".mozilla", "firefox", "efgh5678.dev"),
+			host: "youtube.com",
+			want: map[string]string{"dev": "dev-value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies, err := FromBrowser(tt.spec)
+			if err != nil {
+				t.Fatalf("FromBrowser() error: %+v", err)
+			}
+			got := cookieMap(Filter(cookies, tt.host))
+			count := tt.count
+			if count == 0 {
+				count = len(tt.want)
+			}
+			if len(got) != count {
+				t.Errorf("got %d cookies, want %d: %v", len(got), count, got)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("cookie %s = %q, want %q", name, got[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestFromBrowserError(t *testing.T) {
+	setHome(t)
+	for _, spec := range []string{"safari", "chromium:Profile 9", "firefox:missing"} {
+		if _, err := FromBrowser(spec); err == nil {
+			t.Errorf("FromBrowser(%q) should return an error", spec)
+		}
+	}
+}
+
+func TestChromiumKeyring(t *testing.T) {
+	password := "keyring-password"
+	keyringPassword = func(string) string { return password }
+	defer func() { keyringPassword = func(string) string { return "" } }()
+
+	// "secret" encrypted with the v11 key derived from the keyring password
+	encrypted, _ := encryptForTest(chromiumKey(password), []byte("secret"))
+	d := newChromiumDecryptor(keyringPassword("chromium"))
+	got, err := d.decrypt(append([]byte("v11"), encrypted...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "secret" {
+		t.Errorf("decrypt() = %q, want %q", got, "secret")
+	}
+}
+
+func TestHeader(t *testing.T) {
+	cookies := []*http.Cookie{{Name: "a", Value: "b"}, {Name: "c", Value: "d"}}
+	if got := Header(cookies); got != "a=b; c=d" {
+		t.Errorf("Header() = %q, want %q", got, "a=b; c=d")
+	}
+}
+
+func encryptForTest(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	plaintext = append(plaintext, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(ciphertext, plaintext)
+	return ciphertext, nil
+}
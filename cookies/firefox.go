@@ -0,0 +1,102 @@
+package cookies
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// firefoxBrowser reads cookies from the cookies.sqlite database of a Firefox profile.
+type firefoxBrowser struct{}
+
+// profileDir returns the given profile directory, the profile whose name matches,
+// or the most recently used profile if no profile is given.
+func (firefoxBrowser) profileDir(profile string) (string, error) {
+	if isDir(profile) {
+		return profile, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	roots := []string{
+		filepath.Join(home, ".mozilla", "firefox"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+	}
+
+	var (
+		dir     string
+		modTime time.Time
+	)
+	for _, root := range roots {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			// profile directories are named like "abcd1234.default-release"
+			if profile != "" && entry.Name() != profile && !strings.HasSuffix(entry.Name(), "."+profile) {
+				continue
+			}
+			info, err := os.Stat(filepath.Join(root, entry.Name(), "cookies.sqlite"))
+			if err != nil {
+				continue
+			}
+			if dir == "" || info.ModTime().After(modTime) {
+				dir = filepath.Join(root, entry.Name())
+				modTime = info.ModTime()
+			}
+		}
+	}
+	if dir == "" {
+		if profile == "" {
+			return "", errors.New("no firefox profile found")
+		}
+		return "", errors.Errorf("firefox profile %q not found", profile)
+	}
+	return dir, nil
+}
+
+func (b firefoxBrowser) cookies(profile string) ([]*http.Cookie, error) {
+	dir, err := b.profileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openSQLite(filepath.Join(dir, "cookies.sqlite"))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.table("moz_cookies")
+	if err != nil {
+		return nil, err
+	}
+	cookies := make([]*http.Cookie, 0, len(rows))
+	for _, row := range rows {
+		cookies = append(cookies, &http.Cookie{
+			Domain:   row.String("host"),
+			Name:     row.String("name"),
+			Value:    row.String("value"),
+			Path:     row.String("path"),
+			Expires:  firefoxTime(row.Int("expiry")),
+			Secure:   row.Int("isSecure") == 1,
+			HttpOnly: row.Int("isHttpOnly") == 1,
+		})
+	}
+	return cookies, nil
+}
+
+// firefoxTime converts the expiry column to time.Time,
+// older versions store seconds while newer versions store milliseconds.
+func firefoxTime(t int64) time.Time {
+	if t > 1e11 {
+		return time.UnixMilli(t)
+	}
+	return time.Unix(t, 0)
+}
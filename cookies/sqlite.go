@@ -0,0 +1,442 @@
+package cookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sqliteDB is a minimal read-only reader of the SQLite 3 file format.
+// It only supports what is needed to read the cookie tables of browsers:
+// walking table b-trees, following overflow pages and applying committed WAL frames.
+// https://www.sqlite.org/fileformat.html
+type sqliteDB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+	// wal holds the latest committed version of the pages found in the -wal file
+	wal map[uint32][]byte
+}
+
+// sqliteRow is a single table row, keyed by column name.
+type sqliteRow map[string]interface{}
+
+func (r sqliteRow) String(column string) string {
+	switch v := r[column].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+func (r sqliteRow) Bytes(column string) []byte {
+	switch v := r[column].(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+func (r sqliteRow) Int(column string) int64 {
+	switch v := r[column].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+const sqliteHeader = "SQLite format 3\x00"
+
+// openSQLite reads the whole database file (and its -wal file if present) into memory,
+// so the database can be read while the browser is still holding it open.
+func openSQLite(path string) (*sqliteDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(data) < 100 || string(data[:16]) != sqliteHeader {
+		return nil, errors.Errorf("%s is not a SQLite 3 database", path)
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	// the page size is a power of two between 512 and 65536, the usable size is at least 480 bytes
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 || pageSize-int(data[20]) < 480 {
+		return nil, errors.Errorf("%s: invalid page size %d", path, pageSize)
+	}
+	if encoding := binary.BigEndian.Uint32(data[56:60]); encoding > 1 {
+		return nil, errors.Errorf("%s: unsupported text encoding %d", path, encoding)
+	}
+	db := &sqliteDB{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(data[20]),
+	}
+	if wal, err := os.ReadFile(path + "-wal"); err == nil {
+		db.wal = parseWAL(wal, pageSize)
+	}
+	return db, nil
+}
+
+// parseWAL returns the pages of all committed transactions in the write-ahead log.
+// Checksums are not verified, frames are only validated by their salt values.
+func parseWAL(wal []byte, pageSize int) map[uint32][]byte {
+	const (
+		walHeaderSize   = 32
+		frameHeaderSize = 24
+	)
+	if len(wal) < walHeaderSize {
+		return nil
+	}
+	magic := binary.BigEndian.Uint32(wal[0:4])
+	if magic != 0x377f0682 && magic != 0x377f0683 {
+		return nil
+	}
+	if int(binary.BigEndian.Uint32(wal[8:12])) != pageSize {
+		return nil
+	}
+	salt := wal[16:24]
+
+	committed := make(map[uint32][]byte)
+	pending := make(map[uint32][]byte)
+	for offset := walHeaderSize; offset+frameHeaderSize+pageSize <= len(wal); offset += frameHeaderSize + pageSize {
+		frame := wal[offset : offset+frameHeaderSize]
+		if !bytes.Equal(frame[8:16], salt) {
+			break
+		}
+		pageNumber := binary.BigEndian.Uint32(frame[0:4])
+		pending[pageNumber] = wal[offset+frameHeaderSize : offset+frameHeaderSize+pageSize]
+		// a non-zero database size marks the last frame of a transaction
+		if binary.BigEndian.Uint32(frame[4:8]) != 0 {
+			for n, p := range pending {
+				committed[n] = p
+			}
+			pending = make(map[uint32][]byte)
+		}
+	}
+	return committed
+}
+
+// page returns the content of the given 1-based page number.
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	if p, ok := db.wal[n]; ok {
+		return p, nil
+	}
+	start := int(n-1) * db.pageSize
+	if n == 0 || start+db.pageSize > len(db.data) {
+		return nil, errors.Errorf("sqlite: page %d out of range", n)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// walk calls fn with the payload of every cell in the table b-tree rooted at the given page.
+func (db *sqliteDB) walk(root uint32, fn func(rowID int64, payload []byte) error) error {
+	return db.walkPage(root, 0, make(map[uint32]bool), fn)
+}
+
+// walkPage walks the b-tree of the page, visited are the pages of the b-tree walked before, a page of a valid
+// b-tree has one parent only, so a page visited twice is a cycle of a corrupted file.
+func (db *sqliteDB) walkPage(n uint32, depth int, visited map[uint32]bool, fn func(rowID int64, payload []byte) error) error {
+	if visited[n] {
+		return errors.Errorf("sqlite: page %d is visited twice", n)
+	}
+	visited[n] = true
+	if depth > 64 {
+		return errors.New("sqlite: b-tree is too deep")
+	}
+	page, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	headerOffset := 0
+	if n == 1 {
+		headerOffset = 100
+	}
+	header := page[headerOffset:]
+	var headerSize int
+	switch header[0] {
+	case 0x05: // table interior page
+		headerSize = 12
+	case 0x0d: // table leaf page
+		headerSize = 8
+	default:
+		return errors.Errorf("sqlite: unexpected page type %d on page %d", header[0], n)
+	}
+	cellCount := int(binary.BigEndian.Uint16(header[3:5]))
+	if headerSize+cellCount*2 > len(header) {
+		return errors.Errorf("sqlite: too many cells on page %d", n)
+	}
+	cellPointers := header[headerSize:]
+	// cell returns the cell i of the page, it has at least size bytes
+	cell := func(i, size int) ([]byte, error) {
+		offset := int(binary.BigEndian.Uint16(cellPointers[i*2:]))
+		if offset < headerOffset+headerSize || offset+size > db.usableSize {
+			return nil, errors.Errorf("sqlite: cell %d out of page %d", i, n)
+		}
+		return page[offset:db.usableSize], nil
+	}
+
+	if header[0] == 0x05 {
+		for i := 0; i < cellCount; i++ {
+			c, err := cell(i, 4)
+			if err != nil {
+				return err
+			}
+			if err := db.walkPage(binary.BigEndian.Uint32(c[0:4]), depth+1, visited, fn); err != nil {
+				return err
+			}
+		}
+		return db.walkPage(binary.BigEndian.Uint32(header[8:12]), depth+1, visited, fn)
+	}
+	for i := 0; i < cellCount; i++ {
+		c, err := cell(i, 2)
+		if err != nil {
+			return err
+		}
+		payloadSize, l1 := readVarint(c)
+		rowID, l2 := readVarint(c[l1:])
+		payload, err := db.payload(c[l1+l2:], payloadSize)
+		if err != nil {
+			return errors.WithMessagef(err, "sqlite: cell %d of page %d", i, n)
+		}
+		if err := fn(rowID, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// payload assembles the full payload of a table leaf cell, following the overflow pages if needed.
+func (db *sqliteDB) payload(cell []byte, payloadSize int64) ([]byte, error) {
+	// the payload can't be larger than all the pages
+	if payloadSize < 0 || payloadSize > int64(len(db.data)+len(db.wal)*db.pageSize) {
+		return nil, errors.Errorf("invalid payload size %d", payloadSize)
+	}
+	size := int(payloadSize)
+	maxLocal := db.usableSize - 35
+	if size <= maxLocal {
+		if size > len(cell) {
+			return nil, errors.New("truncated payload")
+		}
+		return cell[:size], nil
+	}
+	minLocal := (db.usableSize-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(db.usableSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	if local+4 > len(cell) {
+		return nil, errors.New("truncated payload")
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, cell[:local]...)
+	next := binary.BigEndian.Uint32(cell[local : local+4])
+	visited := make(map[uint32]bool)
+	for next != 0 && len(payload) < size {
+		if visited[next] {
+			return nil, errors.Errorf("sqlite: overflow page %d is visited twice", next)
+		}
+		visited[next] = true
+		page, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		remaining := size - len(payload)
+		if remaining > db.usableSize-4 {
+			remaining = db.usableSize - 4
+		}
+		payload = append(payload, page[4:4+remaining]...)
+		next = binary.BigEndian.Uint32(page[0:4])
+	}
+	if len(payload) != size {
+		return nil, errors.New("sqlite: truncated overflow chain")
+	}
+	return payload, nil
+}
+
+// table returns all rows of the given table.
+func (db *sqliteDB) table(name string) ([]sqliteRow, error) {
+	var (
+		root    uint32
+		columns []string
+	)
+	// page 1 is the root of the sqlite_schema table: type, name, tbl_name, rootpage, sql
+	err := db.walk(1, func(_ int64, payload []byte) error {
+		values, err := decodeRecord(payload)
+		if err != nil {
+			return err
+		}
+		if len(values) < 5 || values[0] != "table" || values[1] != name {
+			return nil
+		}
+		rootPage, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+		root = uint32(rootPage)
+		columns = parseColumns(sql)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if root == 0 {
+		return nil, errors.Errorf("sqlite: no such table: %s", name)
+	}
+
+	var rows []sqliteRow
+	err = db.walk(root, func(_ int64, payload []byte) error {
+		values, err := decodeRecord(payload)
+		if err != nil {
+			return err
+		}
+		row := make(sqliteRow, len(columns))
+		for i, column := range columns {
+			// columns added by ALTER TABLE may be missing in older records
+			if i < len(values) {
+				row[column] = values[i]
+			}
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseColumns extracts the column names from a CREATE TABLE statement.
+func parseColumns(sql string) []string {
+	start := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+
+	var (
+		definitions []string
+		depth       int
+		last        int
+	)
+	body := sql[start+1 : end]
+	for i, c := range body {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				definitions = append(definitions, body[last:i])
+				last = i + 1
+			}
+		}
+	}
+	definitions = append(definitions, body[last:])
+
+	columns := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		fields := strings.Fields(definition)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "CONSTRAINT":
+			// table constraints, not columns
+			continue
+		}
+		columns = append(columns, strings.Trim(fields[0], "\"`[]"))
+	}
+	return columns
+}
+
+// decodeRecord decodes a record into its values:
+// nil, int64, float64, string or []byte.
+func decodeRecord(payload []byte) ([]interface{}, error) {
+	headerSize, n := readVarint(payload)
+	if headerSize < int64(n) || headerSize > int64(len(payload)) {
+		return nil, errors.New("sqlite: malformed record")
+	}
+	var serialTypes []int64
+	for offset := n; offset < int(headerSize); {
+		serialType, l := readVarint(payload[offset:])
+		serialTypes = append(serialTypes, serialType)
+		offset += l
+	}
+
+	values := make([]interface{}, 0, len(serialTypes))
+	body := payload[headerSize:]
+	for _, serialType := range serialTypes {
+		size := serialTypeSize(serialType)
+		if size > len(body) {
+			return nil, errors.New("sqlite: malformed record")
+		}
+		field := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType >= 1 && serialType <= 6:
+			// big-endian two's complement integers
+			v := int64(int8(field[0]))
+			for _, b := range field[1:] {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(field)))
+		case serialType == 8:
+			values = append(values, int64(0))
+		case serialType == 9:
+			values = append(values, int64(1))
+		case serialType >= 12 && serialType%2 == 0:
+			values = append(values, field)
+		case serialType >= 13:
+			values = append(values, string(field))
+		default:
+			return nil, errors.Errorf("sqlite: unsupported serial type %d", serialType)
+		}
+	}
+	return values, nil
+}
+
+func serialTypeSize(serialType int64) int {
+	switch {
+	case serialType >= 1 && serialType <= 4:
+		return int(serialType)
+	case serialType == 5:
+		return 6
+	case serialType == 6 || serialType == 7:
+		return 8
+	case serialType >= 12:
+		return int(serialType-12) / 2
+	}
+	return 0
+}
+
+// readVarint reads a SQLite variable-length integer and returns it with the number of bytes read.
+func readVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return int64(v<<8 | uint64(b[i])), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return int64(v), i + 1
+		}
+	}
+	return int64(v), len(b)
+}
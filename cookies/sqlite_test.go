@@ -0,0 +1,77 @@
+package cookies
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSQLiteCorrupted reads truncated and damaged copies of a database, they must fail without panicking.
+func TestSQLiteCorrupted(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "home", ".config", "chromium", "Default", "Cookies"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "Cookies")
+	read := func(t *testing.T, data []byte) error {
+		t.Helper()
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		db, err := openSQLite(path)
+		if err != nil {
+			return err
+		}
+		_, err = db.table("cookies")
+		return err
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		for _, size := range []int{0, 99, 100, 600, 4096, 4097, len(data) / 2, len(data) - 1} {
+			if err := read(t, data[:size]); err == nil {
+				t.Errorf("reading the first %d bytes should fail", size)
+			}
+		}
+	})
+
+	t.Run("damaged", func(t *testing.T) {
+		for offset := 100; offset < len(data); offset += 31 {
+			damaged := append([]byte(nil), data...)
+			damaged[offset] ^= 0xff
+			// only the panics matter, a damaged value may still be read
+			read(t, damaged) // nolint
+		}
+	})
+
+	t.Run("page size", func(t *testing.T) {
+		damaged := append([]byte(nil), data...)
+		damaged[16], damaged[17] = 0x01, 0x01
+		if err := read(t, damaged); err == nil {
+			t.Error("reading a database with an invalid page size should fail")
+		}
+	})
+}
+
+func TestSQLiteCycle(t *testing.T) {
+	// page 1 is an interior page whose right-most child is page 2, the child of page 2 is page 1 again
+	data := make([]byte, 1024)
+	copy(data, sqliteHeader)
+	binary.BigEndian.PutUint16(data[16:18], 512)
+	data[100] = 0x05
+	binary.BigEndian.PutUint32(data[108:112], 2)
+	data[512] = 0x05
+	binary.BigEndian.PutUint32(data[520:524], 1)
+	path := filepath.Join(t.TempDir(), "Cookies")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := openSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.table("cookies"); err == nil || !strings.Contains(err.Error(), "visited twice") {
+		t.Errorf("table() error = %v, want the cycle", err)
+	}
+}
@@ -0,0 +1,65 @@
+package downloader
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/utils"
+)
+
+// metadataEscaper escapes the special characters of the values in the FFMETADATA format.
+var metadataEscaper = strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n")
+
+// chapterMetadata returns the chapters in the FFMETADATA format of ffmpeg, the offsets are in milliseconds.
+func chapterMetadata(chapters []extractors.Chapter) string {
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+	for _, c := range chapters {
+		fmt.Fprintf(&b, "[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n", // nolint
+			int64(c.Start*1000), int64(c.End*1000), metadataEscaper.Replace(c.Title))
+	}
+	return b.String()
+}
+
+// chapters embeds the chapters of the data into the video, and splits the video into one file per chapter,
+// the files are named "title - 001 chapter". The video itself is kept.
+func (downloader *Downloader) chapters(data *extractors.Data, videoPath, title, ext string) error {
+	if len(data.Chapters) == 0 || !(downloader.option.EmbedChapters || downloader.option.SplitChapters) {
+		return nil
+	}
+
+	if downloader.option.EmbedChapters {
+		if !downloader.option.Silent {
+			fmt.Println("Embedding chapters...")
+		}
+		metadataPath := videoPath + ".ffmetadata"
+		if err := os.WriteFile(metadataPath, []byte(chapterMetadata(data.Chapters)), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+		defer os.Remove(metadataPath) // nolint
+		if err := utils.EmbedChapters(videoPath, metadataPath); err != nil {
+			return err
+		}
+	}
+
+	if downloader.option.SplitChapters {
+		for i, c := range data.Chapters {
+			name := utils.FileName(fmt.Sprintf("%s - %03d %s", title, i+1, c.Title), "", downloader.option.FileNameLength)
+			chapterPath, err := utils.FilePath(name, ext, downloader.option.FileNameLength, downloader.option.OutputPath, false)
+			if err != nil {
+				return err
+			}
+			if !downloader.option.Silent {
+				fmt.Printf("Splitting chapter %d into %s\n", i+1, chapterPath)
+			}
+			if err = utils.CutFile(videoPath, chapterPath, c.Start, c.End); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/cheggaaa/pb/v3"
@@ -26,26 +27,40 @@ import (
 
 // Options defines options used in downloading.
 type Options struct {
-	InfoOnly       bool
-	Silent         bool
-	Stream         string
-	AudioOnly      bool
-	Refer          string
-	OutputPath     string
+	InfoOnly   bool
+	Silent     bool
+	Stream     string
+	AudioOnly  bool
+	Refer      string
+	OutputPath string
+	// OutputName is the output file name, it can be a template of the Data, eg: "{{.Site}} {{.Title}}".
 	OutputName     string
 	FileNameLength int
 	Caption        bool
 	EmbedSubtitle  bool
+	// EmbedChapters writes the chapters of the video into its metadata, SplitChapters cuts the video into
+	// one file per chapter, both need ffmpeg.
+	EmbedChapters bool
+	SplitChapters bool
+	// Section is the time range of the video to download, nil means the whole video. Only the segments
+	// covering it are downloaded if their durations are known, the video is cut to it with ffmpeg.
+	Section *Section
 
 	MultiThread  bool
 	ThreadNumber int
-	RetryTimes   int
-	ChunkSizeMB  int
+	// Deprecated: the retries follow the retry policy of Client, see request.Options.RetryTimes.
+	RetryTimes  int
+	ChunkSizeMB int
 	// Aria2
 	UseAria2RPC bool
 	Aria2Token  string
 	Aria2Method string
 	Aria2Addr   string
+
+	// Client sends the download requests, nil means the default client.
+	Client *request.Client
+	// Progress is called with the downloaded and total bytes of the stream while downloading, and once more at the end.
+	Progress func(current, total int64)
 }
 
 // Downloader is the default downloader.
@@ -58,6 +73,33 @@ const (
 	DOWNLOAD_FILE_EXT = ".download"
 )
 
+// progressInterval is how often Options.Progress is called.
+const progressInterval = 500 * time.Millisecond
+
+// reportProgress calls Options.Progress with the progress of the bar until the returned function is called.
+func (downloader *Downloader) reportProgress(bar *pb.ProgressBar) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				downloader.option.Progress(bar.Current(), bar.Total())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		downloader.option.Progress(bar.Current(), bar.Total())
+	}
+}
+
 func progressBar(size int64) *pb.ProgressBar {
 	tmpl := `{{counters .}} {{bar . "[" "=" ">" "-" "]"}} {{speed .}} {{percent . | green}} {{rtime .}}`
 	return pb.New64(size).
@@ -80,7 +122,7 @@ func (downloader *Downloader) caption(url, fileName, ext string, transform func(
 	if refer == "" {
 		refer = url
 	}
-	body, err := request.GetByte(url, refer, nil)
+	body, err := downloader.option.Client.GetByte(url, refer, nil)
 	if err != nil {
 		return err
 	}
@@ -109,7 +151,7 @@ func (downloader *Downloader) caption(url, fileName, ext string, transform func(
 }
 
 func (downloader *Downloader) writeFile(url string, file *os.File, headers map[string]string) (int64, error) {
-	res, err := request.Request(http.MethodGet, url, nil, headers)
+	res, err := downloader.option.Client.DownloadRequest(http.MethodGet, url, nil, headers)
 	if err != nil {
 		return 0, err
 	}
@@ -120,12 +162,15 @@ func (downloader *Downloader) writeFile(url string, file *os.File, headers map[s
 	// So don't worry about memory.
 	written, copyErr := io.Copy(barWriter, res.Body)
 	if copyErr != nil && copyErr != io.EOF {
-		return written, errors.Errorf("file copy error: %s", copyErr)
+		return written, errors.Wrap(copyErr, "file copy error")
 	}
 	return written, nil
 }
 
-func (downloader *Downloader) save(part *extractors.Part, refer, fileName string) error {
+func (downloader *Downloader) save(part *extractors.Part, refer, fileName string) (err error) {
+	if len(part.Segments) > 0 {
+		return downloader.saveSegments(part, refer, fileName)
+	}
 	filePath, err := utils.FilePath(fileName, part.Ext, downloader.option.FileNameLength, downloader.option.OutputPath, false)
 	if err != nil {
 		return err
@@ -170,11 +215,13 @@ func (downloader *Downloader) save(part *extractors.Part, refer, fileName string
 		// must close the file before rename or it will cause
 		// `The process cannot access the file because it is being used by another process.` error.
 		file.Close() // nolint
+		// err is the result, the incomplete file is kept to resume the download
 		if err == nil {
 			os.Rename(tempFilePath, filePath) // nolint
 		}
 	}()
 
+	policy := downloader.option.Client.RetryPolicy(part.URL)
 	if downloader.option.ChunkSizeMB > 0 {
 		var start, end, chunkSize int64
 		chunkSize = int64(downloader.option.ChunkSizeMB) * 1024 * 1024
@@ -196,12 +243,14 @@ func (downloader *Downloader) save(part *extractors.Part, refer, fileName string
 				written, err := downloader.writeFile(part.URL, file, headers)
 				if err == nil {
 					break
-				} else if i+1 >= downloader.option.RetryTimes {
+				}
+				delay, ok := policy.Next(i, err)
+				if !ok {
 					return err
 				}
 				temp += written
 				headers["Range"] = fmt.Sprintf("bytes=%d-%d", temp, end)
-				time.Sleep(1 * time.Second)
+				time.Sleep(delay)
 			}
 			start = end + 1
 		}
@@ -211,19 +260,84 @@ func (downloader *Downloader) save(part *extractors.Part, refer, fileName string
 			written, err := downloader.writeFile(part.URL, file, headers)
 			if err == nil {
 				break
-			} else if i+1 >= downloader.option.RetryTimes {
+			}
+			delay, ok := policy.Next(i, err)
+			if !ok {
 				return err
 			}
 			temp += written
 			headers["Range"] = fmt.Sprintf("bytes=%d-", temp)
-			time.Sleep(1 * time.Second)
+			time.Sleep(delay)
 		}
 	}
 
 	return nil
 }
 
+// saveSegments downloads the segments of a DASH track in order into the file of the part, an interrupted
+// download starts again from the first segment.
+func (downloader *Downloader) saveSegments(part *extractors.Part, refer, fileName string) (err error) {
+	filePath, err := utils.FilePath(fileName, part.Ext, downloader.option.FileNameLength, downloader.option.OutputPath, false)
+	if err != nil {
+		return err
+	}
+	// the file is renamed once all the segments are downloaded
+	fileSize, exists, err := utils.FileSize(filePath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		downloader.Bar.Add64(fileSize)
+		return nil
+	}
+
+	tempFilePath := filePath + DOWNLOAD_FILE_EXT
+	file, err := os.Create(tempFilePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close() // nolint
+		if err == nil {
+			os.Rename(tempFilePath, filePath) // nolint
+		}
+	}()
+
+	headers := map[string]string{
+		"Referer": refer,
+	}
+	for _, segment := range part.Segments {
+		start, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		policy := downloader.option.Client.RetryPolicy(segment.URL)
+		for i := 0; ; i++ {
+			_, err := downloader.writeFile(segment.URL, file, headers)
+			if err == nil {
+				break
+			}
+			delay, ok := policy.Next(i, err)
+			if !ok {
+				return err
+			}
+			// the segment is downloaded again from its start
+			if err = file.Truncate(start); err != nil {
+				return errors.WithStack(err)
+			}
+			if _, err = file.Seek(start, io.SeekStart); err != nil {
+				return errors.WithStack(err)
+			}
+			time.Sleep(delay)
+		}
+	}
+	return nil
+}
+
 func (downloader *Downloader) multiThreadSave(dataPart *extractors.Part, refer, fileName string) error {
+	if len(dataPart.Segments) > 0 {
+		return downloader.saveSegments(dataPart, refer, fileName)
+	}
 	filePath, err := utils.FilePath(fileName, dataPart.Ext, downloader.option.FileNameLength, downloader.option.OutputPath, false)
 	if err != nil {
 		return err
@@ -337,6 +451,7 @@ func (downloader *Downloader) multiThreadSave(dataPart *extractors.Part, refer,
 		}
 	}
 
+	policy := downloader.option.Client.RetryPolicy(dataPart.URL)
 	wgp := utils.NewWaitGroupPool(downloader.option.ThreadNumber)
 	var errs []error
 	var mu sync.Mutex
@@ -384,7 +499,9 @@ func (downloader *Downloader) multiThreadSave(dataPart *extractors.Part, refer,
 					if err == nil {
 						remainingSize -= chunkSize
 						break
-					} else if i+1 >= downloader.option.RetryTimes {
+					}
+					delay, ok := policy.Next(i, err)
+					if !ok {
 						mu.Lock()
 						errs = append(errs, err)
 						mu.Unlock()
@@ -392,6 +509,7 @@ func (downloader *Downloader) multiThreadSave(dataPart *extractors.Part, refer,
 					}
 					temp += written
 					headers["Range"] = fmt.Sprintf("bytes=%d-%d", temp, end)
+					time.Sleep(delay)
 				}
 				part.Cur = end + 1
 			}
@@ -516,10 +634,19 @@ func (downloader *Downloader) aria2(title string, stream *extractors.Stream) err
 	rpcData.Params[0] = "token:" + downloader.option.Aria2Token
 	var urls []string
 	for _, p := range stream.Parts {
+		if len(p.Segments) > 0 {
+			return errors.New("the segments of DASH tracks can not be downloaded with aria2")
+		}
 		urls = append(urls, p.URL)
 	}
 	var inputs Aria2Input
 	inputs.Header = append(inputs.Header, "Referer: "+downloader.option.Refer)
+	// the RPC server is usually local, the token must not go through the proxy of the downloads
+	client := http.Client{
+		Transport: downloader.option.Client.DirectTransport(),
+		Timeout:   30 * time.Second,
+	}
+	defer client.CloseIdleConnections()
 	for i := range urls {
 		rpcData.Params[1] = urls[i : i+1]
 		inputs.Out = fmt.Sprintf("%s[%d].%s", title, i, stream.Parts[0].Ext)
@@ -535,7 +662,6 @@ func (downloader *Downloader) aria2(title string, stream *extractors.Stream) err
 		}
 		req.Header.Set("Content-Type", "application/json")
 
-		var client = http.Client{Timeout: 30 * time.Second}
 		res, err := client.Do(req)
 		if err != nil {
 			return err
@@ -548,6 +674,25 @@ func (downloader *Downloader) aria2(title string, stream *extractors.Stream) err
 	return nil
 }
 
+// outputName returns the output file name of the data, name is used as a template if it contains "{{".
+func outputName(name string, data *extractors.Data) (string, error) {
+	if name == "" {
+		return data.Title, nil
+	}
+	if !strings.Contains(name, "{{") {
+		return name, nil
+	}
+	tmpl, err := template.New("output").Parse(name)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid output name template")
+	}
+	var buf strings.Builder
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", errors.Wrap(err, "invalid output name template")
+	}
+	return buf.String(), nil
+}
+
 // Download download urls
 func (downloader *Downloader) Download(data *extractors.Data) error {
 	if len(data.Streams) == 0 {
@@ -560,9 +705,9 @@ func (downloader *Downloader) Download(data *extractors.Data) error {
 		return nil
 	}
 
-	title := downloader.option.OutputName
-	if title == "" {
-		title = data.Title
+	title, err := outputName(downloader.option.OutputName, data)
+	if err != nil {
+		return err
 	}
 	title = utils.FileName(title, "", downloader.option.FileNameLength)
 
@@ -633,6 +778,22 @@ func (downloader *Downloader) Download(data *extractors.Data) error {
 		}
 	}
 
+	// only the segments covering the section are downloaded, offsets are the starts of the part files in the video
+	var offsets []float64
+	if section := downloader.option.Section; section != nil {
+		if downloader.option.UseAria2RPC {
+			return errors.New("a section can not be downloaded with aria2, the files can not be cut")
+		}
+		if stream, offsets, err = sectionStream(stream, *section); err != nil {
+			return err
+		}
+		sectionData := *data
+		sectionData.Chapters = sectionChapters(data.Chapters, *section)
+		data = &sectionData
+	}
+	// the segments of an HLS stream are always merged into the file of the stream
+	segmented := downloader.option.Section != nil && hlsSegments(stream.Parts)
+
 	// Use aria2 rpc to download
 	if downloader.option.UseAria2RPC {
 		return downloader.aria2(title, stream)
@@ -657,7 +818,10 @@ func (downloader *Downloader) Download(data *extractors.Data) error {
 	if !downloader.option.Silent {
 		downloader.Bar.Start()
 	}
-	if len(stream.Parts) == 1 {
+	if downloader.option.Progress != nil {
+		defer downloader.reportProgress(downloader.Bar)()
+	}
+	if len(stream.Parts) == 1 && !segmented {
 		// only one fragment
 		var err error
 		if downloader.option.MultiThread {
@@ -671,18 +835,30 @@ func (downloader *Downloader) Download(data *extractors.Data) error {
 		}
 		downloader.Bar.Finish()
 
+		// the part is saved with its own extension, eg: a single flv part of an mp4 stream
+		filePath, err := utils.FilePath(title, stream.Parts[0].Ext, downloader.option.FileNameLength, downloader.option.OutputPath, false)
+		if err != nil {
+			return err
+		}
+
+		if downloader.option.Section != nil {
+			if err = downloader.trim(filePath, offsets[0]); err != nil {
+				return err
+			}
+		}
+
 		if downloader.option.EmbedSubtitle && len(subtitlePaths) > 0 {
 			if !downloader.option.Silent {
 				fmt.Println("Embedding subtitles...")
 			}
-			if err := utils.EmbedSubtitles(mergedFilePath, subtitlePaths, subtitleLangs); err != nil {
+			if err := utils.EmbedSubtitles(filePath, subtitlePaths, subtitleLangs); err != nil {
 				return err
 			}
 			for _, path := range subtitleFilesToDelete {
 				os.Remove(path)
 			}
 		}
-		return nil
+		return downloader.chapters(data, filePath, title, stream.Parts[0].Ext)
 	}
 
 	wgp := utils.NewWaitGroupPool(downloader.option.ThreadNumber)
@@ -728,6 +904,18 @@ func (downloader *Downloader) Download(data *extractors.Data) error {
 	}
 	downloader.Bar.Finish()
 
+	// the video and audio files of NeedMux streams are cut one by one, their segments may start at different times
+	if downloader.option.Section != nil && (stream.NeedMux || downloader.option.AudioOnly) {
+		for index, partFilePath := range parts {
+			if partFilePath == "" {
+				continue
+			}
+			if err := downloader.trim(partFilePath, offsets[index]); err != nil {
+				return err
+			}
+		}
+	}
+
 	if data.Type != extractors.DataTypeVideo || downloader.option.AudioOnly {
 		return nil
 	}
@@ -745,6 +933,13 @@ func (downloader *Downloader) Download(data *extractors.Data) error {
 		}
 	}
 
+	// the parts of the other streams follow each other, the merged file is cut
+	if downloader.option.Section != nil && !stream.NeedMux {
+		if err := downloader.trim(mergedFilePath, offsets[0]); err != nil {
+			return err
+		}
+	}
+
 	if downloader.option.EmbedSubtitle && len(subtitlePaths) > 0 {
 		if !downloader.option.Silent {
 			fmt.Println("Embedding subtitles...")
@@ -757,5 +952,5 @@ func (downloader *Downloader) Download(data *extractors.Data) error {
 		}
 	}
 
-	return nil
+	return downloader.chapters(data, mergedFilePath, title, stream.Ext)
 }
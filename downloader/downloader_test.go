@@ -1,6 +1,12 @@
 package downloader
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/iawia002/lux/extractors"
@@ -93,9 +99,186 @@ func TestDownload(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		err := New(Options{}).Download(testCase.data)
+		err := New(Options{OutputPath: t.TempDir()}).Download(testCase.data)
 		if err != nil {
 			t.Error(err)
 		}
 	}
 }
+
+func TestOutputName(t *testing.T) {
+	data := &extractors.Data{Site: "douyin", Title: "test"}
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "title", in: "", want: "test"},
+		{name: "fixed name", in: "video", want: "video"},
+		{name: "template", in: "{{.Site}} - {{.Title}}", want: "douyin - test"},
+		{name: "invalid template", in: "{{.Unknown}}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := outputName(tt.in, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("outputName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("outputName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChapterMetadata(t *testing.T) {
+	got := chapterMetadata([]extractors.Chapter{
+		{Start: 0, End: 90.5, Title: "Intro"},
+		{Start: 90.5, End: 200, Title: "a=b; #1"},
+	})
+	want := ";FFMETADATA1\n" +
+		"[CHAPTER]\nTIMEBASE=1/1000\nSTART=0\nEND=90500\ntitle=Intro\n" +
+		"[CHAPTER]\nTIMEBASE=1/1000\nSTART=90500\nEND=200000\ntitle=" + `a\=b\; \#1` + "\n"
+	if got != want {
+		t.Errorf("chapterMetadata() = %q, want %q", got, want)
+	}
+}
+
+func TestParseSection(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    *Section
+		wantErr bool
+	}{
+		{in: "*10:00-10:30", want: &Section{Start: 600, End: 630}},
+		{in: "*1:02:03.5-1:02:10", want: &Section{Start: 3723.5, End: 3730}},
+		{in: "*90-inf", want: &Section{Start: 90}},
+		{in: "*0-15.25", want: &Section{End: 15.25}},
+		{in: "10:00-10:30", wantErr: true},
+		{in: "*10:00", wantErr: true},
+		{in: "*10:30-10:00", wantErr: true},
+		{in: "*inf-10:00", wantErr: true},
+		{in: "*1.5:00-2:00", wantErr: true},
+		{in: "*a-b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSection(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSection() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSectionStream(t *testing.T) {
+	hls := &extractors.Stream{Parts: []*extractors.Part{
+		{URL: "0", Duration: 10}, {URL: "1", Duration: 10}, {URL: "2", Duration: 10}, {URL: "3", Duration: 10},
+	}}
+	dash := &extractors.Stream{NeedMux: true, Parts: []*extractors.Part{
+		{URL: "video", Segments: []extractors.Segment{{URL: "v"}, {URL: "v0", Duration: 4}, {URL: "v1", Duration: 4}, {URL: "v2", Duration: 4}}},
+		{URL: "audio", Segments: []extractors.Segment{{URL: "a"}, {URL: "a0", Duration: 5}, {URL: "a1", Duration: 5}, {URL: "a2", Duration: 2}}},
+	}}
+	progressive := &extractors.Stream{NeedMux: true, Parts: []*extractors.Part{{URL: "video"}, {URL: "audio"}}}
+	tests := []struct {
+		name        string
+		stream      *extractors.Stream
+		section     Section
+		want        []string
+		wantOffsets []float64
+		wantErr     bool
+	}{
+		{name: "hls", stream: hls, section: Section{Start: 12, End: 25}, want: []string{"1", "2"}, wantOffsets: []float64{10, 10}},
+		{name: "hls boundaries", stream: hls, section: Section{Start: 10, End: 20}, want: []string{"1"}, wantOffsets: []float64{10}},
+		{name: "hls to the end", stream: hls, section: Section{Start: 35}, want: []string{"3"}, wantOffsets: []float64{30}},
+		{name: "hls after the end", stream: hls, section: Section{Start: 50, End: 60}, wantErr: true},
+		{
+			name: "dash", stream: dash, section: Section{Start: 5, End: 7},
+			want: []string{"video v v1", "audio a a1"}, wantOffsets: []float64{4, 5},
+		},
+		{name: "dash after the end", stream: dash, section: Section{Start: 12.5}, wantErr: true},
+		{name: "progressive", stream: progressive, section: Section{Start: 12, End: 25}, want: []string{"video", "audio"}, wantOffsets: []float64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, offsets, err := sectionStream(tt.stream, tt.section)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sectionStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, p := range stream.Parts {
+				urls := []string{p.URL}
+				for _, s := range p.Segments {
+					urls = append(urls, s.URL)
+				}
+				got = append(got, strings.Join(urls, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(offsets, tt.wantOffsets) {
+				t.Errorf("sectionStream() = %v, %v, want %v, %v", got, offsets, tt.want, tt.wantOffsets)
+			}
+		})
+	}
+}
+
+func TestSectionChapters(t *testing.T) {
+	chapters := []extractors.Chapter{
+		{Start: 0, End: 60, Title: "Intro"},
+		{Start: 60, End: 600, Title: "Main"},
+		{Start: 600, End: 700, Title: "Outro"},
+	}
+	got := sectionChapters(chapters, Section{Start: 30, End: 120})
+	want := []extractors.Chapter{
+		{Start: 0, End: 30, Title: "Intro"},
+		{Start: 30, End: 90, Title: "Main"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sectionChapters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSaveSegments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.TrimPrefix(r.URL.Path, "/"))) // nolint
+	}))
+	defer server.Close()
+
+	outputPath := t.TempDir()
+	data := &extractors.Data{
+		Site:  "dash",
+		Title: "segments",
+		Type:  extractors.DataTypeVideo,
+		URL:   server.URL,
+		Streams: map[string]*extractors.Stream{
+			"default": {
+				ID:  "default",
+				Ext: "mp4",
+				Parts: []*extractors.Part{{
+					URL: server.URL + "/manifest.mpd",
+					Ext: "mp4",
+					Segments: []extractors.Segment{
+						{URL: server.URL + "/init"},
+						{URL: server.URL + "/0", Duration: 4},
+						{URL: server.URL + "/1", Duration: 4},
+					},
+				}},
+			},
+		},
+	}
+	if err := New(Options{Silent: true, OutputPath: outputPath}).Download(data); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(outputPath, "segments.mp4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "init01" {
+		t.Errorf("the file is %q, want %q", got, "init01")
+	}
+}
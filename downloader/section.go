@@ -0,0 +1,217 @@
+package downloader
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/utils"
+)
+
+// Section is a time range of a video in seconds, End is 0 for the end of the video.
+type Section struct {
+	Start float64
+	End   float64
+}
+
+// ParseSection parses the time range of --download-sections, eg: "*10:00-10:30", "*1:02:03.5-inf".
+// The times are [[hh:]mm:]ss[.ms], "inf" is the end of the video.
+func ParseSection(s string) (*Section, error) {
+	r, ok := strings.CutPrefix(strings.TrimSpace(s), "*")
+	if !ok {
+		return nil, errors.Errorf("invalid section %q, only time ranges are supported, eg: *10:00-10:30", s)
+	}
+	startString, endString, ok := strings.Cut(r, "-")
+	if !ok {
+		return nil, errors.Errorf("invalid section %q, the range needs a start and an end, eg: *10:00-10:30", s)
+	}
+	start, err := parseTimestamp(startString)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid section %q", s)
+	}
+	if math.IsInf(start, 1) {
+		return nil, errors.Errorf("invalid section %q, the start can not be inf", s)
+	}
+	end, err := parseTimestamp(endString)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid section %q", s)
+	}
+	if math.IsInf(end, 1) {
+		end = 0
+	} else if end <= start {
+		return nil, errors.Errorf("invalid section %q, the end must be after the start", s)
+	}
+	return &Section{Start: start, End: end}, nil
+}
+
+// parseTimestamp parses [[hh:]mm:]ss[.ms] into seconds, "inf" is +Inf.
+func parseTimestamp(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "inf" {
+		return math.Inf(1), nil
+	}
+	fields := strings.Split(s, ":")
+	if len(fields) > 3 {
+		return 0, errors.Errorf("invalid time %q", s)
+	}
+	var seconds float64
+	for i, f := range fields {
+		// only the seconds can have a fraction
+		if i < len(fields)-1 && strings.Contains(f, ".") {
+			return 0, errors.Errorf("invalid time %q", s)
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return 0, errors.Errorf("invalid time %q", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, nil
+}
+
+// coveringSegments returns the range [first, last) of the segments covering the section and the start of the
+// first one in the video, ok is false if a duration is unknown.
+func coveringSegments(durations []float64, section Section) (first, last int, offset float64, ok bool, err error) {
+	if len(durations) == 0 {
+		return 0, 0, 0, false, nil
+	}
+	var position float64
+	for _, d := range durations {
+		if d <= 0 {
+			return 0, 0, 0, false, nil
+		}
+		position += d
+	}
+	if section.Start >= position {
+		return 0, 0, 0, false, errors.Errorf("the section starts after the end of the video at %.3fs", position)
+	}
+
+	first, last, position = -1, len(durations), 0
+	for i, d := range durations {
+		start, end := position, position+d
+		position = end
+		if first < 0 && end > section.Start {
+			first, offset = i, start
+		}
+		if section.End > 0 && end >= section.End {
+			last = i + 1
+			break
+		}
+	}
+	return first, last, offset, true, nil
+}
+
+// sectionStream returns a copy of the stream with only the segments covering the section, and the starts in
+// the video of the files of the parts. The parts of HLS streams are the segments, their offsets are the start
+// of the first one, the files of DASH tracks start at their first segment, the other files at 0.
+func sectionStream(stream *extractors.Stream, section Section) (*extractors.Stream, []float64, error) {
+	offsets := make([]float64, len(stream.Parts))
+	durations := make([]float64, len(stream.Parts))
+	for i, p := range stream.Parts {
+		durations[i] = p.Duration
+	}
+	first, last, offset, ok, err := coveringSegments(durations, section)
+	if err != nil {
+		return nil, nil, err
+	}
+	selected := *stream
+	if ok {
+		selected.Parts = stream.Parts[first:last]
+		offsets = offsets[first:last]
+		for i := range offsets {
+			offsets[i] = offset
+		}
+	} else {
+		selected.Parts = make([]*extractors.Part, len(stream.Parts))
+		for i, p := range stream.Parts {
+			selected.Parts[i] = p
+			if len(p.Segments) == 0 {
+				continue
+			}
+			// the initialization segments are always downloaded
+			init := 0
+			for init < len(p.Segments) && p.Segments[init].Duration <= 0 {
+				init++
+			}
+			durations := make([]float64, 0, len(p.Segments)-init)
+			for _, s := range p.Segments[init:] {
+				durations = append(durations, s.Duration)
+			}
+			first, last, offset, ok, err := coveringSegments(durations, section)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				continue
+			}
+			part := *p
+			part.Segments = append(slices.Clip(p.Segments[:init]), p.Segments[init+first:init+last]...)
+			selected.Parts[i], offsets[i] = &part, offset
+		}
+	}
+
+	if ok {
+		selected.Size = 0
+		for _, p := range selected.Parts {
+			selected.Size += p.Size
+		}
+	}
+	return &selected, offsets, nil
+}
+
+// hlsSegments reports whether the parts are the segments of an HLS stream with known durations.
+func hlsSegments(parts []*extractors.Part) bool {
+	for _, p := range parts {
+		if p.Duration <= 0 {
+			return false
+		}
+	}
+	return len(parts) > 0
+}
+
+// sectionChapters returns the chapters inside the section, the times are relative to the start of the section.
+func sectionChapters(chapters []extractors.Chapter, section Section) []extractors.Chapter {
+	var result []extractors.Chapter
+	for _, c := range chapters {
+		if c.End <= section.Start || (section.End > 0 && c.Start >= section.End) {
+			continue
+		}
+		end := c.End
+		if section.End > 0 {
+			end = min(end, section.End)
+		}
+		result = append(result, extractors.Chapter{
+			Start: max(c.Start, section.Start) - section.Start,
+			End:   end - section.Start,
+			Title: c.Title,
+		})
+	}
+	return result
+}
+
+// trim cuts the section out of the file without re-encoding, offset is the start of the file in the video.
+func (downloader *Downloader) trim(filePath string, offset float64) error {
+	section := downloader.option.Section
+	start := max(section.Start-offset, 0)
+	var end float64
+	if section.End > 0 {
+		end = section.End - offset
+	}
+	if !downloader.option.Silent {
+		fmt.Printf("Cutting the section out of %s\n", filePath)
+	}
+
+	ext := filepath.Ext(filePath)
+	sectionPath := strings.TrimSuffix(filePath, ext) + ".section" + ext
+	if err := utils.CutFile(filePath, sectionPath, start, end); err != nil {
+		return err
+	}
+	return errors.WithStack(os.Rename(sectionPath, filePath))
+}
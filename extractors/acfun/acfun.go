@@ -15,7 +15,7 @@ import (
 )
 
 func init() {
-	extractors.Register("acfun", New())
+	extractors.Register("acfun", New(), extractors.CapabilityPlaylist)
 }
 
 const (
@@ -36,7 +36,7 @@ func New() extractors.Extractor {
 
 // Extract ...
 func (e *extractor) Extract(URL string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.GetByte(URL, referer, nil)
+	html, err := option.Client.GetByte(URL, referer, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -69,7 +69,7 @@ func (e *extractor) Extract(URL string, option extractors.Options) ([]*extractor
 		wgp.Add()
 		go func() {
 			defer wgp.Done()
-			datas = append(datas, extractBangumi(concatURL(t)))
+			datas = append(datas, extractBangumi(option.Client, concatURL(t)))
 		}()
 	}
 	wgp.Wait()
@@ -80,9 +80,9 @@ func concatURL(epData *episodeData) string {
 	return fmt.Sprintf(bangumiHTMLURL, epData.BangumiID, epData.ItemID)
 }
 
-func extractBangumi(URL string) *extractors.Data {
+func extractBangumi(client *request.Client, URL string) *extractors.Data {
 	var err error
-	html, err := request.GetByte(URL, referer, nil)
+	html, err := client.GetByte(URL, referer, nil)
 	if err != nil {
 		return extractors.EmptyData(URL, err)
 	}
@@ -100,14 +100,14 @@ func extractBangumi(URL string) *extractors.Data {
 			return extractors.EmptyData(URL, err)
 		}
 
-		urls, err := utils.M3u8URLs(m3u8URL.String())
+		segments, err := utils.M3u8Segments(client, m3u8URL.String())
 		if err != nil {
 			_, err = url.Parse(stm.URL)
 			if err != nil {
 				return extractors.EmptyData(URL, err)
 			}
 
-			urls, err = utils.M3u8URLs(stm.BackURL)
+			segments, err = utils.M3u8Segments(client, stm.BackURL)
 			if err != nil {
 				return extractors.EmptyData(URL, err)
 			}
@@ -115,10 +115,11 @@ func extractBangumi(URL string) *extractors.Data {
 
 		// There is no size information in the m3u8 file and the calculation will take too much time, just ignore it.
 		parts := make([]*extractors.Part, 0)
-		for _, u := range urls {
+		for _, s := range segments {
 			parts = append(parts, &extractors.Part{
-				URL: u,
-				Ext: "ts",
+				URL:      s.URL,
+				Ext:      "ts",
+				Duration: s.Duration,
 			})
 		}
 		streams[stm.QualityLabel] = &extractors.Stream{
@@ -0,0 +1,68 @@
+package extractors
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/netrc"
+	"github.com/iawia002/lux/request"
+)
+
+// Authenticator is implemented by the extractors of the sites that need to log in,
+// the session cookies are kept in the cookie jar of the request client, so they are shared by
+// the following requests and saved with the cookie jar.
+type Authenticator interface {
+	// LoggedIn reports whether the client has a session, the cookie jar drops the expired cookies.
+	LoggedIn(client *request.Client) bool
+	// Login logs in with the username and password.
+	Login(client *request.Client, username, password string) error
+}
+
+// authLock makes sure the same session is not logged in twice at the same time.
+var authLock sync.Mutex
+
+// credentials returns the username and password of the site from the options, or from the .netrc file.
+func credentials(option Options, site string) (string, string, error) {
+	if option.Username != "" || !option.Netrc {
+		return option.Username, option.Password, nil
+	}
+	machine := option.NetrcMachine
+	if machine == "" {
+		machine = site
+	}
+	m, err := netrc.Lookup(netrc.DefaultPath(), machine)
+	if err != nil {
+		return "", "", err
+	}
+	if m == nil {
+		return "", "", errors.Errorf("no login information of %s in .netrc", machine)
+	}
+	return m.Login, m.Password, nil
+}
+
+// authenticate logs in if the extractor supports it and there is no session yet, force logs in anyway.
+// It returns false if the extractor doesn't need to log in or no credentials are given.
+func authenticate(e Extractor, site string, option Options, force bool) (bool, error) {
+	auth, ok := e.(Authenticator)
+	if !ok {
+		return false, nil
+	}
+	username, password, err := credentials(option, site)
+	if err != nil {
+		return false, err
+	}
+	if username == "" {
+		return false, nil
+	}
+
+	authLock.Lock()
+	defer authLock.Unlock()
+	if !force && auth.LoggedIn(option.Client) {
+		return true, nil
+	}
+	if err = auth.Login(option.Client, username, password); err != nil {
+		return true, errors.Wrapf(err, "failed to log in to %s", site)
+	}
+	return true, nil
+}
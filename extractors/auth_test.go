@@ -0,0 +1,98 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/request"
+)
+
+type fakeAuthExtractor struct {
+	loggedIn bool
+	logins   []string
+	// expired makes the next extraction fail as if the session has expired on the server side
+	expired bool
+}
+
+func (e *fakeAuthExtractor) Extract(url string, _ Options) ([]*Data, error) {
+	if e.expired {
+		e.expired = false
+		return nil, errors.WithStack(&request.HTTPError{URL: url, StatusCode: 403})
+	}
+	return []*Data{{URL: url}}, nil
+}
+
+func (e *fakeAuthExtractor) LoggedIn(*request.Client) bool {
+	return e.loggedIn
+}
+
+func (e *fakeAuthExtractor) Login(_ *request.Client, username, password string) error {
+	e.logins = append(e.logins, username+":"+password)
+	e.loggedIn = true
+	return nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	e := &fakeAuthExtractor{}
+	Register("authtest", e)
+	defer Register("authtest", nil)
+	url := "https://www.authtest.com/video/1"
+
+	if _, err := Extract(url, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.logins) != 0 {
+		t.Errorf("logged in without credentials: %v", e.logins)
+	}
+
+	option := Options{Username: "alice", Password: "secret"}
+	for i := 0; i < 2; i++ {
+		if _, err := Extract(url, option); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(e.logins) != 1 || e.logins[0] != "alice:secret" {
+		t.Errorf("the session should be reused, logins: %v", e.logins)
+	}
+
+	e.expired = true
+	if _, err := Extract(url, option); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.logins) != 2 {
+		t.Errorf("an expired session should be logged in again, logins: %v", e.logins)
+	}
+
+	netrc := filepath.Join(t.TempDir(), ".netrc")
+	if err := os.WriteFile(netrc, []byte("machine authtest login bob password hunter2\nmachine other login carol password pw\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NETRC", netrc)
+	tests := []struct {
+		name   string
+		option Options
+		want   string
+	}{
+		{name: "site machine", option: Options{Netrc: true}, want: "bob:hunter2"},
+		{name: "netrc machine", option: Options{Netrc: true, NetrcMachine: "other"}, want: "carol:pw"},
+		{name: "username takes precedence", option: Options{Netrc: true, Username: "alice", Password: "secret"}, want: "alice:secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.loggedIn = false
+			if _, err := Extract(url, tt.option); err != nil {
+				t.Fatal(err)
+			}
+			if got := e.logins[len(e.logins)-1]; got != tt.want {
+				t.Errorf("logged in as %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Extract(url, Options{Netrc: true, NetrcMachine: "missing"}); err == nil {
+		t.Error("a missing netrc machine should fail")
+	}
+}
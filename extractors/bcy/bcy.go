@@ -8,12 +8,11 @@ import (
 
 	"github.com/iawia002/lux/extractors"
 	"github.com/iawia002/lux/parser"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("bcy", New())
+	extractors.Register("bcy", New(), extractors.CapabilityImages)
 }
 
 type bcyData struct {
@@ -35,7 +34,7 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -62,12 +61,12 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	parts := make([]*extractors.Part, 0, len(data.Detail.PostData.Multi))
 	var totalSize int64
 	for _, img := range data.Detail.PostData.Multi {
-		size, err := request.Size(img.OriginalPath, url)
+		size, err := option.Client.Size(img.OriginalPath, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 		totalSize += size
-		_, ext, err := utils.GetNameAndExt(img.OriginalPath)
+		_, ext, err := utils.GetNameAndExt(option.Client, img.OriginalPath)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
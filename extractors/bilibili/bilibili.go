@@ -3,10 +3,11 @@ package bilibili
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"slices"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -18,60 +19,85 @@ import (
 )
 
 func init() {
-	bilibiliExtractor := New()
-	extractors.Register("bilibili", bilibiliExtractor)
-	extractors.Register("b23", bilibiliExtractor)
+	extractors.Register("bilibili", New(), extractors.CapabilityPlaylist, extractors.CapabilityCaptions)
+	extractors.RegisterShortener("b23.tv", nil)
+	extractors.RegisterShortID("bilibili", extractors.ShortID{
+		Pattern: regexp.MustCompile(`^(av|BV)\w+`),
+		Format:  "https://www.bilibili.com/video/%s",
+	})
+	extractors.RegisterShortID("bilibili", extractors.ShortID{
+		Pattern: regexp.MustCompile(`^ep\w+`),
+		Format:  "https://www.bilibili.com/bangumi/play/%s",
+	})
 }
 
 const (
-	bilibiliAPI        = "https://api.bilibili.com/x/player/playurl?"
+	bilibiliAPI        = "https://api.bilibili.com/x/player/wbi/playurl?"
 	bilibiliBangumiAPI = "https://api.bilibili.com/pgc/player/web/playurl?"
 	bilibiliTokenAPI   = "https://api.bilibili.com/x/player/playurl/token?"
+	bilibiliSeasonAPI  = "https://api.bilibili.com/pgc/view/web/season?"
 )
 
 const referer = "https://www.bilibili.com"
 
-var utoken string
+// fnval asks for the DASH streams with HDR, 4K, Dolby Atmos, Dolby Vision, 8K and AV1, the streams that the
+// cookie is not allowed to play are left out by the API.
+const fnval = 16 | 64 | 128 | 256 | 512 | 1024 | 2048
+
+// userToken is the token of the cookie, it is fetched once per extraction and shared by the videos of a playlist.
+type userToken struct {
+	once  sync.Once
+	token string
+	err   error
+}
+
+// get returns the token, the first video asks for it.
+func (t *userToken) get(client *request.Client, aid, cid int) (string, error) {
+	t.once.Do(func() {
+		jsonString, err := getAPI(client, fmt.Sprintf("%said=%d&cid=%d", bilibiliTokenAPI, aid, cid), referer)
+		if err != nil {
+			t.err = err
+			return
+		}
+		var data token
+		if err = json.Unmarshal([]byte(jsonString), &data); err != nil {
+			t.err = errors.WithStack(err)
+			return
+		}
+		if data.Code != 0 {
+			t.err = errors.Errorf("cookie error: %s", data.Message)
+			return
+		}
+		t.token = data.Data.Token
+	})
+	return t.token, t.err
+}
 
-func genAPI(aid, cid, quality int, bvid string, bangumi bool, cookie string) (string, error) {
+func genAPI(client *request.Client, aid, cid, quality int, bvid string, bangumi bool, cookie string, token *userToken) (string, error) {
 	var (
 		err        error
 		baseAPIURL string
 		params     string
+		utoken     string
 	)
-	if cookie != "" && utoken == "" {
-		utoken, err = request.Get(
-			fmt.Sprintf("%said=%d&cid=%d", bilibiliTokenAPI, aid, cid),
-			referer,
-			nil,
-		)
-		if err != nil {
-			return "", err
-		}
-		var t token
-		err = json.Unmarshal([]byte(utoken), &t)
+	if cookie != "" {
+		utoken, err = token.get(client, aid, cid)
 		if err != nil {
 			return "", err
 		}
-		if t.Code != 0 {
-			return "", errors.Errorf("cookie error: %s", t.Message)
-		}
-		utoken = t.Data.Token
 	}
 	var api string
 	if bangumi {
-		// The parameters need to be sorted by name
 		// qn=0 flag makes the CDN address different every time
-		// quality=120(4k) is the highest quality so far
 		params = fmt.Sprintf(
-			"cid=%d&bvid=%s&qn=%d&type=&otype=json&fourk=1&fnver=0&fnval=16",
-			cid, bvid, quality,
+			"cid=%d&bvid=%s&qn=%d&type=&otype=json&fourk=1&fnver=0&fnval=%d",
+			cid, bvid, quality, fnval,
 		)
 		baseAPIURL = bilibiliBangumiAPI
 	} else {
 		params = fmt.Sprintf(
-			"avid=%d&cid=%d&bvid=%s&qn=%d&type=&otype=json&fourk=1&fnver=0&fnval=2000",
-			aid, cid, bvid, quality,
+			"avid=%d&cid=%d&bvid=%s&qn=%d&type=&otype=json&fourk=1&fnver=0&fnval=%d",
+			aid, cid, bvid, quality, fnval,
 		)
 		baseAPIURL = bilibiliAPI
 	}
@@ -84,101 +110,133 @@ func genAPI(aid, cid, quality int, bvid string, bangumi bool, cookie string) (st
 }
 
 type bilibiliOptions struct {
-	url      string
-	html     string
-	bangumi  bool
-	aid      int
-	cid      int
-	bvid     string
-	page     int
+	url     string
+	html    string
+	bangumi bool
+	aid     int
+	cid     int
+	bvid    string
+	page    int
+	// title is the title of the video, it is the title of the page if it is empty
+	title    string
 	subtitle string
 }
 
-func extractBangumi(url, html string, extractOption extractors.Options) ([]*extractors.Data, error) {
-	dataString := utils.MatchOneOf(html, `const playurlSSRData = ({[\s\S]+})`)[1]
-	epArrayString := utils.MatchOneOf(dataString, `"episode_info"\s*:\s*(.+?)\s*,\s*"season_info"`)[1]
-	fullVideoIdString := utils.MatchOneOf(dataString, `"videoId"\s*:\s*"(ep|ss)(\d+)"`)
-	epSsString := fullVideoIdString[1] // "ep" or "ss"
-	videoIdString := fullVideoIdString[2]
+// bangumiEpisode is an episode of a season with its number, eg: "S01E02", the PVs and specials are in season 0.
+type bangumiEpisode struct {
+	seasonEpisode
+	number string
+}
 
-	var epArray EpVideoInfo
-	err := json.Unmarshal([]byte(epArrayString), &epArray)
+// getSeason gets the season of the episode or season ID, eg: "ep167000" or "ss5050".
+func getSeason(client *request.Client, id string) (*seasonInfo, error) {
+	param := "ep_id=" + id[2:]
+	if strings.HasPrefix(id, "ss") {
+		param = "season_id=" + id[2:]
+	}
+	jsonString, err := getAPI(client, bilibiliSeasonAPI+param, referer)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	var data bangumiData
-
-	videoId, err := strconv.ParseInt(videoIdString, 10, 0)
-	if err != nil {
+	var res seasonResponse
+	if err = json.Unmarshal([]byte(jsonString), &res); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	if epArray.EpID == int(videoId) || (epSsString == "ss" && epArray.Title == "第1话") {
-		data.EpInfo = epArray
+	if res.Code != 0 {
+		return nil, errors.Errorf("season %s error: %s", id, res.Message)
 	}
-	data.EpList = append(data.EpList, epArray)
-
-	sort.Slice(data.EpList, func(i, j int) bool {
-		return data.EpList[i].EpID < data.EpList[j].EpID
-	})
+	return &res.Result, nil
+}
 
-	if !extractOption.Playlist {
-		aid := data.EpInfo.Aid
-		cid := data.EpInfo.Cid
-		bvid := data.EpInfo.Bvid
-		titleFormat := data.EpInfo.Title
-		longTitle := data.EpInfo.LongTitle
-		if aid <= 0 || cid <= 0 || bvid == "" {
-			aid = data.EpList[0].Aid
-			cid = data.EpList[0].Cid
-			bvid = data.EpList[0].Bvid
-			titleFormat = data.EpList[0].Title
-			longTitle = data.EpList[0].LongTitle
+// seasonEpisodes lists the episodes of the season, then its PVs and specials.
+func seasonEpisodes(season *seasonInfo) []bangumiEpisode {
+	// the number of the season is its position in the series
+	number := 1
+	for i, s := range season.Seasons {
+		if s.SeasonID == season.SeasonID {
+			number = i + 1
+			break
 		}
-		options := bilibiliOptions{
-			url:     url,
-			html:    html,
-			bangumi: true,
-			aid:     aid,
-			cid:     cid,
-			bvid:    bvid,
+	}
+	episodes := make([]bangumiEpisode, 0, len(season.Episodes))
+	for i, ep := range season.Episodes {
+		episodes = append(episodes, bangumiEpisode{ep, fmt.Sprintf("S%02dE%02d", number, i+1)})
+	}
+	special := 0
+	for _, section := range season.Section {
+		for _, ep := range section.Episodes {
+			special++
+			episodes = append(episodes, bangumiEpisode{ep, fmt.Sprintf("S00E%02d", special)})
+		}
+	}
+	return episodes
+}
+
+// bangumiOptions returns the options of the episode, its title is the title of the season.
+func bangumiOptions(season *seasonInfo, ep bangumiEpisode) bilibiliOptions {
+	subtitle := ep.ShowTitle
+	if subtitle == "" {
+		subtitle = strings.TrimSpace(ep.Title + " " + ep.LongTitle)
+	}
+	return bilibiliOptions{
+		url:      fmt.Sprintf("https://www.bilibili.com/bangumi/play/ep%d", ep.EpID),
+		bangumi:  true,
+		aid:      ep.Aid,
+		cid:      ep.Cid,
+		bvid:     ep.Bvid,
+		title:    season.Title,
+		subtitle: ep.number + " " + subtitle,
+	}
+}
 
-			subtitle: fmt.Sprintf("%s %s", titleFormat, longTitle),
+func extractBangumi(url, html string, extractOption extractors.Options, token *userToken) ([]*extractors.Data, error) {
+	ids := utils.MatchOneOf(url, `/bangumi/play/((?:ep|ss)\d+)`)
+	if ids == nil {
+		ids = utils.MatchOneOf(html, `"videoId"\s*:\s*"((?:ep|ss)\d+)"`)
+	}
+	if ids == nil {
+		return nil, errors.WithStack(extractors.ErrURLParseFailed)
+	}
+	id := ids[1]
+	season, err := getSeason(extractOption.Client, id)
+	if err != nil {
+		return nil, err
+	}
+	episodes := seasonEpisodes(season)
+	if len(episodes) == 0 {
+		return nil, errors.Errorf("season %s has no episodes", id)
+	}
+
+	if !extractOption.Playlist {
+		// the first episode of the season if the URL is not an episode
+		episode := episodes[0]
+		for _, ep := range episodes {
+			if "ep"+strconv.Itoa(ep.EpID) == id {
+				episode = ep
+				break
+			}
 		}
-		return []*extractors.Data{bilibiliDownload(options, extractOption)}, nil
+		return []*extractors.Data{bilibiliDownload(bangumiOptions(season, episode), extractOption, token)}, nil
 	}
 
 	// handle bangumi playlist
-	needDownloadItems := utils.NeedDownloadList(extractOption.Items, extractOption.ItemStart, extractOption.ItemEnd, len(data.EpList))
+	needDownloadItems := utils.NeedDownloadList(extractOption.Items, extractOption.ItemStart, extractOption.ItemEnd, len(episodes))
 	extractedData := make([]*extractors.Data, len(needDownloadItems))
 	wgp := utils.NewWaitGroupPool(extractOption.ThreadNumber)
 	dataIndex := 0
-	for index, u := range data.EpList {
+	for index, ep := range episodes {
 		if !slices.Contains(needDownloadItems, index+1) {
 			continue
 		}
 		wgp.Add()
-		id := u.EpID
-		if id == 0 {
-			id = u.EpID
-		}
-		// html content can't be reused here
-		options := bilibiliOptions{
-			url:     fmt.Sprintf("https://www.bilibili.com/bangumi/play/ep%d", id),
-			bangumi: true,
-			aid:     u.Aid,
-			cid:     u.Cid,
-			bvid:    u.Bvid,
-
-			subtitle: fmt.Sprintf("%s %s", u.Title, u.LongTitle),
-		}
 		go func(index int, options bilibiliOptions, extractedData []*extractors.Data) {
 			defer wgp.Done()
-			extractedData[index] = bilibiliDownload(options, extractOption)
-		}(dataIndex, options, extractedData)
+			extractedData[index] = bilibiliDownload(options, extractOption, token)
+		}(dataIndex, bangumiOptions(season, ep), extractedData)
 		dataIndex++
 	}
 	wgp.Wait()
-	return extractedData, nil
+	return extractedData[:dataIndex], nil
 }
 
 func getMultiPageData(html string) (*multiPage, error) {
@@ -196,7 +254,7 @@ func getMultiPageData(html string) (*multiPage, error) {
 	return &data, nil
 }
 
-func extractFestival(url, html string, extractOption extractors.Options) ([]*extractors.Data, error) {
+func extractFestival(url, html string, extractOption extractors.Options, token *userToken) ([]*extractors.Data, error) {
 	matches := utils.MatchAll(html, "<\\s*script[^>]*>\\s*window\\.__INITIAL_STATE__=([\\s\\S]*?);\\s?\\(function[\\s\\S]*?<\\/\\s*script\\s*>")
 	if len(matches) < 1 {
 		return nil, errors.WithStack(extractors.ErrURLParseFailed)
@@ -220,10 +278,10 @@ func extractFestival(url, html string, extractOption extractors.Options) ([]*ext
 		page: 0,
 	}
 
-	return []*extractors.Data{bilibiliDownload(options, extractOption)}, nil
+	return []*extractors.Data{bilibiliDownload(options, extractOption, token)}, nil
 }
 
-func extractNormalVideo(url, html string, extractOption extractors.Options) ([]*extractors.Data, error) {
+func extractNormalVideo(url, html string, extractOption extractors.Options, token *userToken) ([]*extractors.Data, error) {
 	pageData, err := getMultiPageData(html)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -261,21 +319,21 @@ func extractNormalVideo(url, html string, extractOption extractors.Options) ([]*
 		} else {
 			options.subtitle = page.Part
 		}
-		return []*extractors.Data{bilibiliDownload(options, extractOption)}, nil
+		return []*extractors.Data{bilibiliDownload(options, extractOption, token)}, nil
 	}
 
 	// handle normal video playlist
 	if len(pageData.Sections) == 0 {
 		// https://www.bilibili.com/video/av20827366/?p=* each video in playlist has different p=?
-		return multiPageDownload(url, html, extractOption, pageData)
+		return multiPageDownload(url, html, extractOption, pageData, token)
 	}
 	// handle another kind of playlist
 	// https://www.bilibili.com/video/av*** each video in playlist has different av/bv id
-	return multiEpisodeDownload(url, html, extractOption, pageData)
+	return multiEpisodeDownload(url, html, extractOption, pageData, token)
 }
 
 // handle multi episode download
-func multiEpisodeDownload(url, html string, extractOption extractors.Options, pageData *multiPage) ([]*extractors.Data, error) {
+func multiEpisodeDownload(url, html string, extractOption extractors.Options, pageData *multiPage, token *userToken) ([]*extractors.Data, error) {
 	needDownloadItems := utils.NeedDownloadList(extractOption.Items, extractOption.ItemStart, extractOption.ItemEnd, len(pageData.Sections[0].Episodes))
 	extractedData := make([]*extractors.Data, len(needDownloadItems))
 	wgp := utils.NewWaitGroupPool(extractOption.ThreadNumber)
@@ -295,7 +353,7 @@ func multiEpisodeDownload(url, html string, extractOption extractors.Options, pa
 		}
 		go func(index int, options bilibiliOptions, extractedData []*extractors.Data) {
 			defer wgp.Done()
-			extractedData[index] = bilibiliDownload(options, extractOption)
+			extractedData[index] = bilibiliDownload(options, extractOption, token)
 		}(dataIndex, options, extractedData)
 		dataIndex++
 	}
@@ -304,7 +362,7 @@ func multiEpisodeDownload(url, html string, extractOption extractors.Options, pa
 }
 
 // handle multi page download
-func multiPageDownload(url, html string, extractOption extractors.Options, pageData *multiPage) ([]*extractors.Data, error) {
+func multiPageDownload(url, html string, extractOption extractors.Options, pageData *multiPage, token *userToken) ([]*extractors.Data, error) {
 	needDownloadItems := utils.NeedDownloadList(extractOption.Items, extractOption.ItemStart, extractOption.ItemEnd, len(pageData.VideoData.Pages))
 	extractedData := make([]*extractors.Data, len(needDownloadItems))
 	wgp := utils.NewWaitGroupPool(extractOption.ThreadNumber)
@@ -325,7 +383,7 @@ func multiPageDownload(url, html string, extractOption extractors.Options, pageD
 		}
 		go func(index int, options bilibiliOptions, extractedData []*extractors.Data) {
 			defer wgp.Done()
-			extractedData[index] = bilibiliDownload(options, extractOption)
+			extractedData[index] = bilibiliDownload(options, extractOption, token)
 		}(dataIndex, options, extractedData)
 		dataIndex++
 	}
@@ -342,28 +400,38 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	var err error
-	html, err := request.Get(url, referer, nil)
+	// set thread number to 1 manually to avoid http 412 error
+	option.ThreadNumber = 1
+
+	// the token of the cookie is fetched once for all the videos of the URL
+	token := &userToken{}
+	kind, fetch, err := matchList(url)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
+	}
+	if fetch != nil {
+		// a list, eg: a space or a favorites folder, is always a playlist
+		return extractList(kind, fetch, option, token)
 	}
 
-	// set thread number to 1 manually to avoid http 412 error
-	option.ThreadNumber = 1
+	html, err := option.Client.Get(url, referer, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
 	if strings.Contains(url, "bangumi") {
 		// handle bangumi
-		return extractBangumi(url, html, option)
+		return extractBangumi(url, html, option, token)
 	} else if strings.Contains(url, "festival") {
-		return extractFestival(url, html, option)
+		return extractFestival(url, html, option, token)
 	} else {
 		// handle normal video
-		return extractNormalVideo(url, html, option)
+		return extractNormalVideo(url, html, option, token)
 	}
 }
 
 // bilibiliDownload is the download function for a single URL
-func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options) *extractors.Data {
+func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options, token *userToken) *extractors.Data {
 	var (
 		err  error
 		html string
@@ -371,21 +439,21 @@ func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options)
 	if options.html != "" {
 		// reuse html string, but this can't be reused in case of playlist
 		html = options.html
-	} else {
-		html, err = request.Get(options.url, referer, nil)
+	} else if options.title == "" {
+		html, err = extractOption.Client.Get(options.url, referer, nil)
 		if err != nil {
 			return extractors.EmptyData(options.url, err)
 		}
 	}
 
 	// Get "accept_quality" and "accept_description"
-	// "accept_description":["超高清 8K","超清 4K","高清 1080P+","高清 1080P","高清 720P","清晰 480P","流畅 360P"],
-	// "accept_quality":[127，120,112,80,48,32,16],
-	api, err := genAPI(options.aid, options.cid, 127, options.bvid, options.bangumi, extractOption.Cookie)
+	// "accept_description":["超高清 8K","杜比视界","真彩 HDR","超清 4K","高清 1080P+","高清 1080P","高清 720P","清晰 480P","流畅 360P"],
+	// "accept_quality":[127,126,125,120,112,80,48,32,16],
+	api, err := genAPI(extractOption.Client, options.aid, options.cid, 127, options.bvid, options.bangumi, extractOption.Cookie, token)
 	if err != nil {
 		return extractors.EmptyData(options.url, err)
 	}
-	jsonString, err := request.Get(api, referer, nil)
+	jsonString, err := getAPI(extractOption.Client, api, referer)
 	if err != nil {
 		return extractors.EmptyData(options.url, err)
 	}
@@ -403,24 +471,13 @@ func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options)
 	}
 
 	var audioPart *extractors.Part
-	if dashData.Streams.Audio != nil {
-		// Get audio part
-		var audioID int
-		audios := map[int]string{}
-		bandwidth := 0
-		for _, stream := range dashData.Streams.Audio {
-			if stream.Bandwidth > bandwidth {
-				audioID = stream.ID
-				bandwidth = stream.Bandwidth
-			}
-			audios[stream.ID] = stream.BaseURL
-		}
-		s, err := request.Size(audios[audioID], referer)
+	if audio := bestAudio(dashData.Streams); audio != nil {
+		s, err := extractOption.Client.Size(audio.BaseURL, referer)
 		if err != nil {
 			return extractors.EmptyData(options.url, err)
 		}
 		audioPart = &extractors.Part{
-			URL:  audios[audioID],
+			URL:  audio.BaseURL,
 			Size: s,
 			Ext:  "m4a",
 		}
@@ -428,7 +485,7 @@ func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options)
 
 	streams := make(map[string]*extractors.Stream, len(dashData.Quality))
 	for _, stream := range dashData.Streams.Video {
-		s, err := request.Size(stream.BaseURL, referer)
+		s, err := extractOption.Client.Size(stream.BaseURL, referer)
 		if err != nil {
 			return extractors.EmptyData(options.url, err)
 		}
@@ -480,11 +537,14 @@ func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options)
 	}
 
 	// get the title
-	doc, err := parser.GetDoc(html)
-	if err != nil {
-		return extractors.EmptyData(options.url, err)
+	title := options.title
+	if title == "" {
+		doc, err := parser.GetDoc(html)
+		if err != nil {
+			return extractors.EmptyData(options.url, err)
+		}
+		title = parser.Title(doc)
 	}
-	title := parser.Title(doc)
 	if options.subtitle != "" {
 		pageString := ""
 		if options.page > 0 {
@@ -497,6 +557,7 @@ func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options)
 		}
 	}
 
+	player := getPlayerInfo(extractOption.Client, options.aid, options.cid)
 	return &extractors.Data{
 		Site:    "哔哩哔哩 bilibili.com",
 		Title:   title,
@@ -509,12 +570,35 @@ func bilibiliDownload(options bilibiliOptions, extractOption extractors.Options)
 					Ext: "xml",
 				},
 			},
-			"subtitle": getSubTitleCaptionPart(options.aid, options.cid),
+			"subtitle": getSubTitleCaptionPart(player),
 		},
-		URL: options.url,
+		Chapters: viewPointChapters(player),
+		URL:      options.url,
+		// the cid is unique to each page and episode
+		ID: strconv.Itoa(options.cid),
 	}
 }
 
+// bestAudio returns the Hi-Res audio if the cookie is allowed to play it, then the Dolby Atmos audio,
+// or the audio with the highest bandwidth.
+func bestAudio(streams dashStreams) *dashStream {
+	if streams.Flac.Audio != nil && streams.Flac.Audio.BaseURL != "" {
+		return streams.Flac.Audio
+	}
+	var best *dashStream
+	for _, audios := range [][]dashStream{streams.Dolby.Audio, streams.Audio} {
+		for i := range audios {
+			if best == nil || audios[i].Bandwidth > best.Bandwidth {
+				best = &audios[i]
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
 func getExtFromMimeType(mimeType string) string {
 	exts := strings.Split(mimeType, "/")
 	if len(exts) == 2 {
@@ -523,27 +607,51 @@ func getExtFromMimeType(mimeType string) string {
 	return "mp4"
 }
 
-func getSubTitleCaptionPart(aid int, cid int) *extractors.CaptionPart {
-	jsonString, err := request.Get(
-		fmt.Sprintf("http://api.bilibili.com/x/player/wbi/v2?aid=%d&cid=%d", aid, cid), referer, nil,
-	)
+// getPlayerInfo returns the subtitles and view points of the video, it is nil if the request fails.
+func getPlayerInfo(client *request.Client, aid int, cid int) *bilibiliWebInterfaceData {
+	jsonString, err := getAPI(client, fmt.Sprintf("https://api.bilibili.com/x/player/wbi/v2?aid=%d&cid=%d", aid, cid), referer)
 	if err != nil {
 		return nil
 	}
 	stu := bilibiliWebInterface{}
-	err = json.Unmarshal([]byte(jsonString), &stu)
-	if err != nil || len(stu.Data.SubtitleInfo.SubtitleList) == 0 {
+	if err = json.Unmarshal([]byte(jsonString), &stu); err != nil {
+		return nil
+	}
+	return &stu.Data
+}
+
+func getSubTitleCaptionPart(player *bilibiliWebInterfaceData) *extractors.CaptionPart {
+	if player == nil || len(player.SubtitleInfo.SubtitleList) == 0 {
 		return nil
 	}
 	return &extractors.CaptionPart{
 		Part: extractors.Part{
-			URL: fmt.Sprintf("https:%s", stu.Data.SubtitleInfo.SubtitleList[0].SubtitleUrl),
+			URL: fmt.Sprintf("https:%s", player.SubtitleInfo.SubtitleList[0].SubtitleUrl),
 			Ext: "srt",
 		},
 		Transform: subtitleTransform,
 	}
 }
 
+// viewPointChapters returns the view points of the video as its chapters.
+func viewPointChapters(player *bilibiliWebInterfaceData) []extractors.Chapter {
+	if player == nil {
+		return nil
+	}
+	var chapters []extractors.Chapter
+	for _, p := range player.ViewPoints {
+		if p.To <= p.From {
+			continue
+		}
+		chapters = append(chapters, extractors.Chapter{
+			Start: float64(p.From),
+			End:   float64(p.To),
+			Title: p.Content,
+		})
+	}
+	return chapters
+}
+
 func subtitleTransform(body []byte) ([]byte, error) {
 	bytes := ""
 	captionData := bilibiliSubtitleFormat{}
@@ -1,6 +1,7 @@
 package bilibili
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/iawia002/lux/extractors"
@@ -33,7 +34,7 @@ func TestBilibili(t *testing.T) {
 			name: "bangumi test",
 			args: test.Args{
 				URL:   "https://www.bilibili.com/bangumi/play/ep167000",
-				Title: "狐妖小红娘 第70话 苏苏智商上线",
+				Title: "狐妖小红娘 S01E70 第70话 苏苏智商上线",
 			},
 		},
 		{
@@ -83,15 +84,77 @@ func TestBilibili(t *testing.T) {
 			if tt.playlist {
 				// for playlist, we don't check the data
 				_, err = New().Extract(tt.args.URL, extractors.Options{
+					Client:       test.Client(t),
 					Playlist:     true,
 					ThreadNumber: 9,
 				})
 				test.CheckError(t, err)
 			} else {
-				data, err = New().Extract(tt.args.URL, extractors.Options{})
+				data, err = New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 				test.CheckError(t, err)
 				test.Check(t, tt.args, data[0])
 			}
 		})
 	}
 }
+
+func TestMatchList(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "https://www.bilibili.com/video/BV1qM4y1w716"},
+		{url: "https://www.bilibili.com/watchlater/#/list", want: "watch later"},
+		{url: "https://www.bilibili.com/list/watchlater?bvid=BV1qM4y1w716", want: "watch later"},
+		{url: "https://space.bilibili.com/2/favlist?fid=1052622027&ftype=create", want: "favorites"},
+		{url: "https://www.bilibili.com/medialist/play/ml1052622027", want: "favorites"},
+		{url: "https://space.bilibili.com/2/channel/seriesdetail?sid=3366", want: "series"},
+		{url: "https://space.bilibili.com/2/lists/3366?type=series", want: "series"},
+		{url: "https://www.bilibili.com/medialist/play/2?business=space_series&business_id=3366", want: "series"},
+		{url: "https://space.bilibili.com/2/channel/collectiondetail?sid=1000", want: "season"},
+		{url: "https://space.bilibili.com/2/lists/1000?type=season", want: "season"},
+		{url: "https://www.bilibili.com/list/2?sid=1000&business=space_collection&business_id=1000", want: "season"},
+		{url: "https://space.bilibili.com/2/video", want: "space"},
+		{url: "https://space.bilibili.com/2/upload/video", want: "space"},
+		{url: "https://space.bilibili.com/2?spm_id_from=333.1007", want: "space"},
+		{url: "https://space.bilibili.com/2/", want: "space"},
+		{url: "https://space.bilibili.com/2/favlist", wantErr: true},
+		{url: "https://space.bilibili.com/2/dynamic", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, _, err := matchList(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("matchList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeasonEpisodes(t *testing.T) {
+	var res seasonResponse
+	err := json.Unmarshal([]byte(`{"code":0,"result":{
+		"season_id":2,"title":"Doctor X 第二季",
+		"episodes":[{"id":11,"title":"1","show_title":"第1话"},{"id":12,"title":"2","long_title":"b"}],
+		"section":[{"title":"PV","episodes":[{"id":13,"title":"PV1","show_title":"PV1"}]}],
+		"seasons":[{"season_id":1},{"season_id":2}]
+	}}`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"S02E01 第1话", "S02E02 2 b", "S00E01 PV1"}
+	episodes := seasonEpisodes(&res.Result)
+	if len(episodes) != len(want) {
+		t.Fatalf("seasonEpisodes() returns %d episodes, want %d", len(episodes), len(want))
+	}
+	for i, ep := range episodes {
+		options := bangumiOptions(&res.Result, ep)
+		if options.subtitle != want[i] || options.title != "Doctor X 第二季" {
+			t.Errorf("episode %d = %q %q, want %q", i+1, options.title, options.subtitle, want[i])
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package bilibili
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
+)
+
+const (
+	bilibiliSpaceAPI      = "https://api.bilibili.com/x/space/wbi/arc/search?mid=%s&ps=%d&pn=%d&order=pubdate"
+	bilibiliFavoritesAPI  = "https://api.bilibili.com/x/v3/fav/resource/list?media_id=%s&ps=%d&pn=%d&platform=web"
+	bilibiliWatchLaterAPI = "https://api.bilibili.com/x/v2/history/toview"
+	bilibiliSeasonListAPI = "https://api.bilibili.com/x/polymer/web-space/seasons_archives_list?mid=%s&season_id=%s&page_size=%d&page_num=%d"
+	bilibiliSeriesAPI     = "https://api.bilibili.com/x/series/archives?mid=%s&series_id=%s&ps=%d&pn=%d&sort=desc"
+
+	listPageSize     = 30
+	favoritePageSize = 20
+)
+
+// listFetcher returns the videos of the page pn of a list, the first page is 1, more reports whether
+// there are more pages.
+type listFetcher func(client *request.Client, pn int) (videos []listVideo, more bool, err error)
+
+// listPattern matches the URLs of a kind of lists, the IDs of the list are the groups of the pattern.
+type listPattern struct {
+	kind    string
+	pattern *regexp.Regexp
+	fetcher func(ids []string) listFetcher
+}
+
+// listPatterns are in order, the favorites, series and seasons of a user are in the space of the user.
+var listPatterns = []listPattern{
+	// https://www.bilibili.com/watchlater/#/list, https://www.bilibili.com/list/watchlater
+	{"watch later", regexp.MustCompile(`bilibili\.com/(?:watchlater|list/watchlater|medialist/play/watchlater)`), watchLater},
+	// https://space.bilibili.com/{mid}/favlist?fid={fid}, https://www.bilibili.com/medialist/detail/ml{fid}
+	{"favorites", regexp.MustCompile(`space\.bilibili\.com/\d+/favlist\?(?:.*&)?fid=(\d+)`), favorites},
+	{"favorites", regexp.MustCompile(`bilibili\.com/(?:medialist/play|medialist/detail|list)/ml(\d+)`), favorites},
+	// https://space.bilibili.com/{mid}/channel/seriesdetail?sid={sid}, https://space.bilibili.com/{mid}/lists/{sid}?type=series
+	{"series", regexp.MustCompile(`space\.bilibili\.com/(\d+)/channel/seriesdetail\?(?:.*&)?sid=(\d+)`), series},
+	{"series", regexp.MustCompile(`space\.bilibili\.com/(\d+)/lists/(\d+)\?(?:.*&)?type=series`), series},
+	{"series", regexp.MustCompile(`bilibili\.com/(?:medialist/play|list)/(\d+)\?(?:.*&)?business=space_series&business_id=(\d+)`), series},
+	// https://space.bilibili.com/{mid}/channel/collectiondetail?sid={sid}, https://space.bilibili.com/{mid}/lists/{sid}?type=season,
+	// the lists without a type are seasons
+	{"season", regexp.MustCompile(`space\.bilibili\.com/(\d+)/channel/collectiondetail\?(?:.*&)?sid=(\d+)`), season},
+	{"season", regexp.MustCompile(`space\.bilibili\.com/(\d+)/lists/(\d+)`), season},
+	{"season", regexp.MustCompile(`bilibili\.com/(?:medialist/play|list)/(\d+)\?(?:.*&)?business=space_collection&business_id=(\d+)`), season},
+	// https://space.bilibili.com/{mid}, https://space.bilibili.com/{mid}/video, https://space.bilibili.com/{mid}/upload/video
+	{"space", regexp.MustCompile(`space\.bilibili\.com/(\d+)(?:/(?:upload/)?video)?/?(?:[?#]|$)`), space},
+}
+
+// spacePattern matches all the pages of a space, the ones that are not lists above are not supported.
+var spacePattern = regexp.MustCompile(`space\.bilibili\.com/\d+`)
+
+// matchList returns the kind and the fetcher of the list of the URL, the fetcher is nil if the URL is not a list.
+// The pages of a space that are not a list return an error, eg: a favorites URL without the folder.
+func matchList(url string) (string, listFetcher, error) {
+	for _, p := range listPatterns {
+		if m := p.pattern.FindStringSubmatch(url); m != nil {
+			return p.kind, p.fetcher(m[1:]), nil
+		}
+	}
+	if spacePattern.MatchString(url) {
+		return "", nil, errors.Errorf("unsupported page of a space %s, use the URL of the uploads, a favorites folder, a series or a season", url)
+	}
+	return "", nil, nil
+}
+
+// getList gets the data of a list API, the private lists need the cookie of their owner.
+func getList(client *request.Client, api, refer string, v interface{}) error {
+	jsonString, err := getAPI(client, api, refer)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var res listResponse
+	if err = json.Unmarshal([]byte(jsonString), &res); err != nil {
+		return errors.WithStack(err)
+	}
+	switch res.Code {
+	case 0:
+	case -101:
+		return errors.Errorf("%s, the list needs the cookie of a logged-in account", res.Message)
+	default:
+		return errors.Errorf("error %d: %s", res.Code, res.Message)
+	}
+	return errors.WithStack(json.Unmarshal(res.Data, v))
+}
+
+func space(ids []string) listFetcher {
+	mid := ids[0]
+	return func(client *request.Client, pn int) ([]listVideo, bool, error) {
+		var videos spaceVideos
+		api := fmt.Sprintf(bilibiliSpaceAPI, mid, listPageSize, pn)
+		if err := getList(client, api, "https://space.bilibili.com/"+mid, &videos); err != nil {
+			return nil, false, err
+		}
+		p := videos.Page
+		return videos.List.Vlist, p.Pn*p.Ps < p.Count, nil
+	}
+}
+
+func favorites(ids []string) listFetcher {
+	fid := ids[0]
+	return func(client *request.Client, pn int) ([]listVideo, bool, error) {
+		var videos favoriteVideos
+		api := fmt.Sprintf(bilibiliFavoritesAPI, fid, favoritePageSize, pn)
+		if err := getList(client, api, referer, &videos); err != nil {
+			return nil, false, err
+		}
+		// the audios in the folder are left out
+		list := make([]listVideo, 0, len(videos.Medias))
+		for _, v := range videos.Medias {
+			if v.Type == 2 {
+				list = append(list, v)
+			}
+		}
+		return list, videos.HasMore, nil
+	}
+}
+
+func watchLater([]string) listFetcher {
+	return func(client *request.Client, _ int) ([]listVideo, bool, error) {
+		var videos watchLaterVideos
+		if err := getList(client, bilibiliWatchLaterAPI, referer, &videos); err != nil {
+			return nil, false, err
+		}
+		// all the videos are in one page
+		return videos.List, false, nil
+	}
+}
+
+func season(ids []string) listFetcher {
+	mid, sid := ids[0], ids[1]
+	return func(client *request.Client, pn int) ([]listVideo, bool, error) {
+		var videos seasonVideos
+		api := fmt.Sprintf(bilibiliSeasonListAPI, mid, sid, listPageSize, pn)
+		if err := getList(client, api, "https://space.bilibili.com/"+mid, &videos); err != nil {
+			return nil, false, err
+		}
+		p := videos.Page
+		return videos.Archives, p.PageNum*p.PageSize < p.Total, nil
+	}
+}
+
+func series(ids []string) listFetcher {
+	mid, sid := ids[0], ids[1]
+	return func(client *request.Client, pn int) ([]listVideo, bool, error) {
+		var videos seriesVideos
+		api := fmt.Sprintf(bilibiliSeriesAPI, mid, sid, listPageSize, pn)
+		if err := getList(client, api, "https://space.bilibili.com/"+mid, &videos); err != nil {
+			return nil, false, err
+		}
+		p := videos.Page
+		return videos.Archives, p.Num*p.Size < p.Total, nil
+	}
+}
+
+// extractList extracts the videos of a list, eg: the videos of a space, a favorites folder or a series,
+// each video is extracted as a normal video.
+func extractList(kind string, fetch listFetcher, extractOption extractors.Options, token *userToken) ([]*extractors.Data, error) {
+	urls, err := extractors.Paginate(extractOption, func(cursor string) (*extractors.Page, error) {
+		pn := 1
+		if cursor != "" {
+			pn, _ = strconv.Atoi(cursor)
+		}
+		videos, more, err := fetch(extractOption.Client, pn)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "bilibili %s", kind)
+		}
+		page := &extractors.Page{}
+		for _, v := range videos {
+			page.URLs = append(page.URLs, "https://www.bilibili.com/video/"+v.Bvid)
+		}
+		if more {
+			page.Next = strconv.Itoa(pn + 1)
+		}
+		return page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return extractors.ExtractItems(urls, extractOption, func(url string) *extractors.Data {
+		return extractVideo(url, extractOption, token)
+	}), nil
+}
+
+// extractVideo extracts the first page of the video of the URL, it is an item of a list.
+func extractVideo(url string, extractOption extractors.Options, token *userToken) *extractors.Data {
+	html, err := extractOption.Client.Get(url, referer, nil)
+	if err != nil {
+		return extractors.EmptyData(url, err)
+	}
+	extractOption.Playlist = false
+	data, err := extractNormalVideo(url, html, extractOption, token)
+	if err != nil {
+		return extractors.EmptyData(url, err)
+	}
+	return data[0]
+}
@@ -1,5 +1,7 @@
 package bilibili
 
+import "encoding/json"
+
 // {"code":0,"message":"0","ttl":1,"data":{"token":"aaa"}}
 // {"code":-101,"message":"账号未登录","ttl":1}
 type tokenData struct {
@@ -12,26 +14,39 @@ type token struct {
 	Data    tokenData `json:"data"`
 }
 
-type Interaction struct {
-	Interaction bool `json:"interaction"`
+// {"code":0,"message":"success","result":{"season_id":1,"title":"a","episodes":[...],"section":[{"title":"PV","episodes":[...]}],"seasons":[...]}}
+type seasonEpisode struct {
+	Aid       int    `json:"aid"`
+	Bvid      string `json:"bvid"`
+	Cid       int    `json:"cid"`
+	EpID      int    `json:"id"`
+	Title     string `json:"title"`
+	LongTitle string `json:"long_title"`
+	ShowTitle string `json:"show_title"`
+}
+
+type seasonSection struct {
+	Title    string          `json:"title"`
+	Episodes []seasonEpisode `json:"episodes"`
 }
 
-type EpVideoInfo struct {
-	Aid                           int         `json:"aid"`
-	Bvid                          string      `json:"bvid"`
-	Cid                           int         `json:"cid"`
-	DeliveryBusinessFragmentVideo bool        `json:"delivery_business_fragment_video"`
-	DeliveryFragmentVideo         bool        `json:"delivery_fragment_video"`
-	EpID                          int         `json:"ep_id"`
-	EpStatus                      int         `json:"ep_status"`
-	Interaction                   Interaction `json:"interaction"`
-	LongTitle                     string      `json:"long_title"`
-	Title                         string      `json:"title"`
+type seasonInfo struct {
+	SeasonID    int             `json:"season_id"`
+	SeasonTitle string          `json:"season_title"`
+	Title       string          `json:"title"`
+	Episodes    []seasonEpisode `json:"episodes"`
+	// Section has the PVs and specials of the season
+	Section []seasonSection `json:"section"`
+	// Seasons are all the seasons of the series, in order
+	Seasons []struct {
+		SeasonID int `json:"season_id"`
+	} `json:"seasons"`
 }
 
-type bangumiData struct {
-	EpInfo EpVideoInfo   `json:"epInfo"`
-	EpList []EpVideoInfo `json:"epList"`
+type seasonResponse struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Result  seasonInfo `json:"result"`
 }
 
 type videoPagesData struct {
@@ -76,6 +91,13 @@ type dashStream struct {
 type dashStreams struct {
 	Video []dashStream `json:"video"`
 	Audio []dashStream `json:"audio"`
+	// Dolby has the Dolby Atmos audios, Flac has the Hi-Res audio
+	Dolby struct {
+		Audio []dashStream `json:"audio"`
+	} `json:"dolby"`
+	Flac struct {
+		Audio *dashStream `json:"audio"`
+	} `json:"flac"`
 }
 
 type dashInfo struct {
@@ -101,6 +123,8 @@ type dash struct {
 
 var qualityString = map[int]string{
 	127: "超高清 8K",
+	126: "杜比视界",
+	125: "真彩 HDR",
 	120: "超清 4K",
 	116: "高清 1080P60",
 	74:  "高清 720P60",
@@ -141,9 +165,19 @@ type subtitleInfo struct {
 	SubtitleList []subtitleProperty `json:"subtitles"`
 }
 
+// {"type":2,"from":0,"to":95,"content":"开场","imgUrl":"..."}
+type viewPoint struct {
+	Type    int    `json:"type"`
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+	Content string `json:"content"`
+}
+
 type bilibiliWebInterfaceData struct {
 	Bvid         string       `json:"bvid"`
 	SubtitleInfo subtitleInfo `json:"subtitle"`
+	// ViewPoints are the chapters of the video
+	ViewPoints []viewPoint `json:"view_points"`
 }
 
 type bilibiliWebInterface struct {
@@ -177,3 +211,62 @@ type festival struct {
 		} `json:"pages"`
 	} `json:"videoInfo"`
 }
+
+// {"code":0,"message":"0","data":{...}}
+// {"code":-101,"message":"账号未登录"}
+type listResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// listVideo is a video of the lists, eg: a favorites folder or a series.
+type listVideo struct {
+	Bvid  string `json:"bvid"`
+	Title string `json:"title"`
+	// Type of the items of a favorites folder, 2 is a video
+	Type int `json:"type"`
+}
+
+// {"list":{"vlist":[{"bvid":"BV1xx","title":"a"}]},"page":{"pn":1,"ps":30,"count":1}}
+type spaceVideos struct {
+	List struct {
+		Vlist []listVideo `json:"vlist"`
+	} `json:"list"`
+	Page struct {
+		Pn    int `json:"pn"`
+		Ps    int `json:"ps"`
+		Count int `json:"count"`
+	} `json:"page"`
+}
+
+// {"info":{"title":"a","media_count":1},"medias":[{"bvid":"BV1xx","title":"a","type":2}],"has_more":false}
+type favoriteVideos struct {
+	Medias  []listVideo `json:"medias"`
+	HasMore bool        `json:"has_more"`
+}
+
+// {"count":1,"list":[{"bvid":"BV1xx","title":"a"}]}
+type watchLaterVideos struct {
+	List []listVideo `json:"list"`
+}
+
+// {"archives":[{"bvid":"BV1xx","title":"a"}],"page":{"page_num":1,"page_size":30,"total":1}}
+type seasonVideos struct {
+	Archives []listVideo `json:"archives"`
+	Page     struct {
+		PageNum  int `json:"page_num"`
+		PageSize int `json:"page_size"`
+		Total    int `json:"total"`
+	} `json:"page"`
+}
+
+// {"archives":[{"bvid":"BV1xx","title":"a"}],"page":{"num":1,"size":30,"total":1}}
+type seriesVideos struct {
+	Archives []listVideo `json:"archives"`
+	Page     struct {
+		Num   int `json:"num"`
+		Size  int `json:"size"`
+		Total int `json:"total"`
+	} `json:"page"`
+}
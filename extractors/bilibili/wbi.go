@@ -0,0 +1,148 @@
+package bilibili
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	netURL "net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/request"
+)
+
+const bilibiliNavAPI = "https://api.bilibili.com/x/web-interface/nav"
+
+// wbiKeyTTL is how long the mixin key of a client is cached, bilibili changes the key once a day.
+const wbiKeyTTL = 10 * time.Minute
+
+// mixinKeyEncTab is the order of the characters of the img and sub keys in the mixin key.
+var mixinKeyEncTab = []int{
+	46, 47, 18, 2, 53, 8, 23, 32, 15, 50, 10, 31, 58, 3, 45, 35, 27, 43, 5, 49,
+	33, 9, 42, 19, 29, 28, 14, 39, 12, 38, 41, 13, 37, 48, 7, 16, 24, 55, 40, 61,
+	26, 17, 0, 1, 60, 51, 30, 4, 22, 25, 54, 21, 56, 59, 6, 63, 57, 62, 11, 36,
+	20, 34, 44, 52,
+}
+
+// {"code":-101,"message":"账号未登录","data":{"isLogin":false,"wbi_img":{"img_url":"https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png","sub_url":"https://i0.hdslb.com/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png"}}}
+type nav struct {
+	Data struct {
+		WbiImg struct {
+			ImgURL string `json:"img_url"`
+			SubURL string `json:"sub_url"`
+		} `json:"wbi_img"`
+	} `json:"data"`
+}
+
+// wbiKey is the mixin key of a client and the time it is fetched.
+type wbiKey struct {
+	key     string
+	fetched time.Time
+}
+
+// wbiSigner signs the requests of the bilibili APIs with the WBI signature, the w_rid and wts parameters.
+// The mixin key is cached per client, the clients may use another proxy or cookie.
+type wbiSigner struct {
+	lock sync.Mutex
+	keys map[*request.Client]wbiKey
+}
+
+var wbi = &wbiSigner{keys: make(map[*request.Client]wbiKey)}
+
+// mixinKey returns the mixin key of the img and sub keys.
+func mixinKey(imgKey, subKey string) string {
+	raw := imgKey + subKey
+	var b strings.Builder
+	for _, i := range mixinKeyEncTab {
+		if i < len(raw) {
+			b.WriteByte(raw[i])
+		}
+	}
+	key := b.String()
+	if len(key) > 32 {
+		key = key[:32]
+	}
+	return key
+}
+
+// mixinKey returns the cached mixin key, it is fetched again once it expires.
+func (s *wbiSigner) mixinKey(client *request.Client) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if k, ok := s.keys[client]; ok && time.Since(k.fetched) < wbiKeyTTL {
+		return k.key, nil
+	}
+
+	// the keys are returned without logging in
+	jsonString, err := client.Get(bilibiliNavAPI, referer, nil)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	var n nav
+	if err = json.Unmarshal([]byte(jsonString), &n); err != nil {
+		return "", errors.WithStack(err)
+	}
+	imgKey := strings.TrimSuffix(path.Base(n.Data.WbiImg.ImgURL), path.Ext(n.Data.WbiImg.ImgURL))
+	subKey := strings.TrimSuffix(path.Base(n.Data.WbiImg.SubURL), path.Ext(n.Data.WbiImg.SubURL))
+	if imgKey == "" || subKey == "" {
+		return "", errors.New("unable to get the wbi keys")
+	}
+	// the expired keys are dropped, the clients of the finished extractions are not kept
+	for c, k := range s.keys {
+		if time.Since(k.fetched) >= wbiKeyTTL {
+			delete(s.keys, c)
+		}
+	}
+	key := mixinKey(imgKey, subKey)
+	s.keys[client] = wbiKey{key: key, fetched: time.Now()}
+	return key, nil
+}
+
+// signQuery returns the query with the wts and w_rid parameters of the mixin key and the time.
+func signQuery(query netURL.Values, key string, now time.Time) string {
+	signed := netURL.Values{}
+	for k, values := range query {
+		for _, v := range values {
+			// the characters are removed from the values before signing
+			signed.Add(k, strings.Map(func(r rune) rune {
+				if strings.ContainsRune("!'()*", r) {
+					return -1
+				}
+				return r
+			}, v))
+		}
+	}
+	signed.Set("wts", strconv.FormatInt(now.Unix(), 10))
+	// Encode sorts the parameters by name, the spaces are encoded as %20 like encodeURIComponent
+	encoded := strings.ReplaceAll(signed.Encode(), "+", "%20")
+	sum := md5.Sum([]byte(encoded + key))
+	return encoded + "&w_rid=" + hex.EncodeToString(sum[:])
+}
+
+// sign returns the api URL with the WBI signature.
+func (s *wbiSigner) sign(client *request.Client, api string) (string, error) {
+	u, err := netURL.Parse(api)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	key, err := s.mixinKey(client)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = signQuery(u.Query(), key, time.Now())
+	return u.String(), nil
+}
+
+// getAPI gets the bilibili API with the WBI signature.
+func getAPI(client *request.Client, api, refer string) (string, error) {
+	signed, err := wbi.sign(client, api)
+	if err != nil {
+		return "", err
+	}
+	return client.Get(signed, refer, nil)
+}
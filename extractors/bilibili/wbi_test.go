@@ -0,0 +1,109 @@
+package bilibili
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iawia002/lux/request"
+)
+
+func TestSignQuery(t *testing.T) {
+	// the example of https://github.com/SocialSisterYi/bilibili-API-collect/blob/master/docs/misc/sign/wbi.md
+	key := mixinKey("7cd084941338484aae1ad9425b84077c", "4932caff0ff746eab6f01bf08b70ac45")
+	if key != "ea1db124af3c7062474693fa704f4ff8" {
+		t.Fatalf("mixinKey() = %s", key)
+	}
+	query := url.Values{"foo": {"114"}, "bar": {"514"}, "zab": {"1919810"}}
+	want := "bar=514&foo=114&wts=1702204169&zab=1919810&w_rid=8f6f2b5b3d485fe1886cec6a0be8c5d4"
+	if got := signQuery(query, key, time.Unix(1702204169, 0)); got != want {
+		t.Errorf("signQuery() = %s, want %s", got, want)
+	}
+}
+
+type navTransport struct {
+	requests int32
+}
+
+func (t *navTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	body := `{"data":{"wbi_img":{"img_url":"https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png","sub_url":"https://i0.hdslb.com/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png"}}}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestWbiKeyCache(t *testing.T) {
+	newClient := func(transport http.RoundTripper) *request.Client {
+		c, err := request.New(request.Options{RetryTimes: 1, Transport: transport})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	first, second := &navTransport{}, &navTransport{}
+	c1, c2 := newClient(first), newClient(second)
+	for i := 0; i < 3; i++ {
+		for _, c := range []*request.Client{c1, c2} {
+			key, err := wbi.mixinKey(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key != "ea1db124af3c7062474693fa704f4ff8" {
+				t.Fatalf("mixinKey() = %s", key)
+			}
+		}
+	}
+	if first.requests != 1 || second.requests != 1 {
+		t.Errorf("the keys are fetched %d and %d times, want once per client", first.requests, second.requests)
+	}
+
+	// an expired key is fetched again
+	wbi.lock.Lock()
+	wbi.keys[c1] = wbiKey{key: "expired", fetched: time.Now().Add(-wbiKeyTTL)}
+	wbi.lock.Unlock()
+	if key, err := wbi.mixinKey(c1); err != nil || key != "ea1db124af3c7062474693fa704f4ff8" {
+		t.Errorf("mixinKey() = %s, %v", key, err)
+	}
+	if first.requests != 2 {
+		t.Errorf("the expired key is fetched %d times, want 2", first.requests)
+	}
+}
+
+func TestBestAudio(t *testing.T) {
+	audios := []dashStream{{ID: 30216, Bandwidth: 67000}, {ID: 30280, Bandwidth: 320000}}
+	tests := []struct {
+		name    string
+		streams dashStreams
+		want    int
+	}{
+		{name: "highest bandwidth", streams: dashStreams{Audio: audios}, want: 30280},
+		{name: "dolby", streams: func() dashStreams {
+			s := dashStreams{Audio: audios}
+			s.Dolby.Audio = []dashStream{{ID: 30250, Bandwidth: 256000}}
+			return s
+		}(), want: 30250},
+		{name: "flac", streams: func() dashStreams {
+			s := dashStreams{Audio: audios}
+			s.Flac.Audio = &dashStream{ID: 30251, BaseURL: "https://upos/flac.m4a"}
+			return s
+		}(), want: 30251},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bestAudio(tt.streams); got == nil || got.ID != tt.want {
+				t.Errorf("bestAudio() = %+v, want %d", got, tt.want)
+			}
+		})
+	}
+	if got := bestAudio(dashStreams{}); got != nil {
+		t.Errorf("bestAudio() = %+v, want nil", got)
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 )
 
 func init() {
@@ -35,7 +34,7 @@ func (e *extractor) Extract(u string, option extractors.Options) ([]*extractors.
 	}
 	embedURL := fmt.Sprintf("https://www.bitchute.com/api/beta9/embed/?videoID=%s", matchVideoID[1])
 
-	res, err := request.Request(http.MethodGet, embedURL, nil, nil)
+	res, err := option.Client.Request(http.MethodGet, embedURL, nil, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -75,7 +74,7 @@ func (e *extractor) Extract(u string, option extractors.Options) ([]*extractors.
 	videoName := strings.ReplaceAll(matchVideoName[1], `\"`, "")
 
 	streams := make(map[string]*extractors.Stream, 1)
-	size, err := request.Size(mediaURL, u)
+	size, err := option.Client.Size(mediaURL, u)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
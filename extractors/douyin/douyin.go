@@ -20,8 +20,9 @@ import (
 
 func init() {
 	e := New()
-	extractors.Register("douyin", e)
-	extractors.Register("iesdouyin", e)
+	extractors.Register("douyin", e, extractors.CapabilityPlaylist, extractors.CapabilityImages)
+	extractors.RegisterRoutes("douyin", e, extractors.Route{Host: "iesdouyin.com"})
+	extractors.RegisterShortener("v.douyin.com", nil)
 }
 
 //go:embed sign.js
@@ -36,24 +37,15 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	if strings.Contains(url, "v.douyin.com") {
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		c := http.Client{
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
-		resp, err := c.Do(req)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		defer resp.Body.Close() // nolint
-		url = resp.Header.Get("location")
+	if secUID := utils.MatchOneOf(url, `douyin\.com/user/([\w-]+)`); secUID != nil {
+		// a user is always the list of the videos of the user
+		return extractUser(secUID[1], option)
 	}
+	return extractVideo(url, option)
+}
 
+// extractVideo extracts the video or images of the URL.
+func extractVideo(url string, option extractors.Options) ([]*extractors.Data, error) {
 	itemIds := utils.MatchOneOf(url, `/video/(\d+)`)
 	if len(itemIds) == 0 {
 		return nil, errors.New("unable to get video ID")
@@ -64,37 +56,15 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	itemId := itemIds[len(itemIds)-1]
 
 	// dynamic generate cookie
-	cookie, err := createCookie()
+	cookie, err := createCookie(option.Client)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
 	api := "https://www.douyin.com/aweme/v1/web/aweme/detail/?aweme_id=" + itemId
-	// parse api query params string
-	query, err := netURL.Parse(api)
+	jsonData, err := signedGet(option.Client, api, url, cookie)
 	if err != nil {
-		return nil, errors.WithStack(extractors.ErrURLQueryParamsParseFailed)
-	}
-	// define request headers and sign agent
-	headers := map[string]string{}
-	headers["Cookie"] = cookie
-	headers["Referer"] = "https://www.douyin.com/"
-	headers["User-Agent"] = "Mozilla/5.0 (Linux; Android 8.0; Pixel 2 Build/OPD3.170816.012) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/87.0.4280.88 Mobile Safari/537.36 Edg/87.0.664.66"
-
-	// init JavaScripts runtime
-	vm := goja.New()
-	// load sign scripts
-	_, _ = vm.RunString(script)
-	// sign
-	sign, err := vm.RunString(fmt.Sprintf("sign('%s', '%s')", query.RawQuery, headers["User-Agent"]))
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-	api = fmt.Sprintf("%s&X-Bogus=%s", api, sign)
-
-	jsonData, err := request.Get(api, url, headers)
-	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
 	}
 	var douyin douyinData
 	if err = json.Unmarshal([]byte(jsonData), &douyin); err != nil {
@@ -109,12 +79,12 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		douyinType = extractors.DataTypeImage
 		for _, img := range douyin.AwemeDetail.Images {
 			realURL := img.URLList[len(img.URLList)-1]
-			size, err := request.Size(realURL, url)
+			size, err := option.Client.Size(realURL, url)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
 			totalSize += size
-			_, ext, err := utils.GetNameAndExt(realURL)
+			_, ext, err := utils.GetNameAndExt(option.Client, realURL)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -127,7 +97,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	} else {
 		douyinType = extractors.DataTypeVideo
 		realURL := douyin.AwemeDetail.Video.PlayAddr.URLList[0]
-		totalSize, err = request.Size(realURL, url)
+		totalSize, err = option.Client.Size(realURL, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -146,6 +116,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 	return []*extractors.Data{
 		{
+			ID:      itemId,
 			Site:    "抖音 douyin.com",
 			Title:   douyin.AwemeDetail.Desc,
 			Type:    douyinType,
@@ -155,15 +126,53 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	}, nil
 }
 
-func createCookie() (string, error) {
+// signedGet requests the API with the X-Bogus signature of its query.
+func signedGet(client *request.Client, api, refer, cookie string) (string, error) {
+	// parse api query params string
+	query, err := netURL.Parse(api)
+	if err != nil {
+		return "", errors.WithStack(extractors.ErrURLQueryParamsParseFailed)
+	}
+	// define request headers and sign agent
+	headers := map[string]string{}
+	headers["Cookie"] = cookie
+	headers["Referer"] = "https://www.douyin.com/"
+	headers["User-Agent"] = "Mozilla/5.0 (Linux; Android 8.0; Pixel 2 Build/OPD3.170816.012) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/87.0.4280.88 Mobile Safari/537.36 Edg/87.0.664.66"
+
+	// init JavaScripts runtime
+	vm := goja.New()
+	// load sign scripts
+	_, _ = vm.RunString(script)
+	// sign
+	sign, err := vm.RunString(fmt.Sprintf("sign('%s', '%s')", query.RawQuery, headers["User-Agent"]))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	api = fmt.Sprintf("%s&X-Bogus=%s", api, sign)
+
+	jsonData, err := client.Get(api, refer, headers)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return jsonData, nil
+}
+
+func createCookie(client *request.Client) (string, error) {
 	v1, err := msToken(107)
 	if err != nil {
 		return "", err
 	}
-	v2, err := ttwid()
+	v2, err := ttwid(client)
 	if err != nil {
 		return "", err
 	}
+	// keep ttwid in the shared cookie jar, so it is reused by later requests and runs
+	client.AddCookies([]*http.Cookie{{
+		Domain: ".douyin.com",
+		Path:   "/",
+		Name:   "ttwid",
+		Value:  v2,
+	}})
 	v3 := "324fb4ea4a89c0c05827e18a1ed9cf9bf8a17f7705fcc793fec935b637867e2a5a9b8168c885554d029919117a18ba69"
 	v4 := "eyJiZC10aWNrZXQtZ3VhcmQtdmVyc2lvbiI6MiwiYmQtdGlja2V0LWd1YXJkLWNsaWVudC1jc3IiOiItLS0tLUJFR0lOIENFUlRJRklDQVRFIFJFUVVFU1QtLS0tLVxyXG5NSUlCRFRDQnRRSUJBREFuTVFzd0NRWURWUVFHRXdKRFRqRVlNQllHQTFVRUF3d1BZbVJmZEdsamEyVjBYMmQxXHJcbllYSmtNRmt3RXdZSEtvWkl6ajBDQVFZSUtvWkl6ajBEQVFjRFFnQUVKUDZzbjNLRlFBNUROSEcyK2F4bXAwNG5cclxud1hBSTZDU1IyZW1sVUE5QTZ4aGQzbVlPUlI4NVRLZ2tXd1FJSmp3Nyszdnc0Z2NNRG5iOTRoS3MvSjFJc3FBc1xyXG5NQ29HQ1NxR1NJYjNEUUVKRGpFZE1Cc3dHUVlEVlIwUkJCSXdFSUlPZDNkM0xtUnZkWGxwYmk1amIyMHdDZ1lJXHJcbktvWkl6ajBFQXdJRFJ3QXdSQUlnVmJkWTI0c0RYS0c0S2h3WlBmOHpxVDRBU0ROamNUb2FFRi9MQnd2QS8xSUNcclxuSURiVmZCUk1PQVB5cWJkcytld1QwSDZqdDg1czZZTVNVZEo5Z2dmOWlmeTBcclxuLS0tLS1FTkQgQ0VSVElGSUNBVEUgUkVRVUVTVC0tLS0tXHJcbiJ9"
 	cookie := fmt.Sprintf("msToken=%s;ttwid=%s;odin_tt=%s;bd_ticket_guard_client_data=%s;", v1, v2, v3, v4)
@@ -183,7 +192,13 @@ func msToken(length int) (string, error) {
 	return string(token), nil
 }
 
-func ttwid() (string, error) {
+func ttwid(client *request.Client) (string, error) {
+	for _, c := range client.Cookies("https://www.douyin.com/") {
+		if c.Name == "ttwid" {
+			return c.Value, nil
+		}
+	}
+
 	body := map[string]interface{}{
 		"aid":           1768,
 		"union":         true,
@@ -198,7 +213,7 @@ func ttwid() (string, error) {
 		return "", err
 	}
 	payload := strings.NewReader(string(bytes))
-	resp, err := request.Request(http.MethodPost, "https://ttwid.bytedance.com/ttwid/union/register/", payload, nil)
+	resp, err := client.Request(http.MethodPost, "https://ttwid.bytedance.com/ttwid/union/register/", payload, nil)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,72 @@
+package douyin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+const userPostAPI = "https://www.douyin.com/aweme/v1/web/aweme/post/?device_platform=webapp&aid=6383&sec_user_id=%s&max_cursor=%s&count=%d"
+
+const userPageSize = 18
+
+// {"status_code":0,"aweme_list":[{"aweme_id":"7000000000000000000","desc":"a"}],"has_more":1,"max_cursor":1700000000000}
+type userPosts struct {
+	StatusCode int `json:"status_code"`
+	AwemeList  []struct {
+		AwemeID string `json:"aweme_id"`
+		Desc    string `json:"desc"`
+	} `json:"aweme_list"`
+	HasMore   int   `json:"has_more"`
+	MaxCursor int64 `json:"max_cursor"`
+}
+
+// extractUser extracts the videos of the user page, eg: https://www.douyin.com/user/{sec_uid},
+// they are numbered from the newest one.
+func extractUser(secUID string, option extractors.Options) ([]*extractors.Data, error) {
+	cookie, err := createCookie(option.Client)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	userURL := "https://www.douyin.com/user/" + secUID
+
+	urls, err := extractors.Paginate(option, func(cursor string) (*extractors.Page, error) {
+		if cursor == "" {
+			cursor = "0"
+		}
+		jsonData, err := signedGet(option.Client, fmt.Sprintf(userPostAPI, secUID, cursor, userPageSize), userURL, cookie)
+		if err != nil {
+			return nil, err
+		}
+		var posts userPosts
+		if err = json.Unmarshal([]byte(jsonData), &posts); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if posts.StatusCode != 0 {
+			return nil, errors.Errorf("douyin user %s error: status code %d", secUID, posts.StatusCode)
+		}
+		page := &extractors.Page{}
+		for _, aweme := range posts.AwemeList {
+			page.URLs = append(page.URLs, "https://www.douyin.com/video/"+aweme.AwemeID)
+		}
+		if posts.HasMore == 1 {
+			page.Next = strconv.FormatInt(posts.MaxCursor, 10)
+		}
+		return page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return extractors.ExtractItems(urls, option, func(url string) *extractors.Data {
+		data, err := extractVideo(url, option)
+		if err != nil {
+			return extractors.EmptyData(url, err)
+		}
+		return data[0]
+	}), nil
+}
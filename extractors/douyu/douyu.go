@@ -26,19 +26,19 @@ type douyuURLInfo struct {
 	Size int64
 }
 
-func douyuM3u8(url string) ([]douyuURLInfo, int64, error) {
+func douyuM3u8(client *request.Client, url string) ([]douyuURLInfo, int64, error) {
 	var (
 		data            []douyuURLInfo
 		temp            douyuURLInfo
 		size, totalSize int64
 		err             error
 	)
-	urls, err := utils.M3u8URLs(url)
+	urls, err := utils.M3u8URLs(client, url)
 	if err != nil {
 		return nil, 0, err
 	}
 	for _, u := range urls {
-		size, err = request.Size(u, url)
+		size, err = client.Size(u, url)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -67,7 +67,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.New("暂不支持斗鱼直播")
 	}
 
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -83,7 +83,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	}
 	vid := vids[1]
 
-	dataString, err := request.Get("http://vmobile.douyu.com/video/getInfo?vid="+vid, url, nil)
+	dataString, err := option.Client.Get("http://vmobile.douyu.com/video/getInfo?vid="+vid, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -92,7 +92,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(err)
 	}
 
-	m3u8URLs, totalSize, err := douyuM3u8(dataDict.Data.VideoURL)
+	m3u8URLs, totalSize, err := douyuM3u8(option.Client, dataDict.Data.VideoURL)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -23,7 +23,7 @@ func TestDownload(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			New().Extract(tt.args.URL, extractors.Options{})
+			New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 		})
 	}
 }
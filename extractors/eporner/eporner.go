@@ -10,7 +10,6 @@ import (
 
 	"github.com/iawia002/lux/extractors"
 	"github.com/iawia002/lux/parser"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -105,7 +104,7 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(u string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(u, u, nil)
+	html, err := option.Client.Get(u, u, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -125,7 +124,7 @@ func (e *extractor) Extract(u string, option extractors.Options) ([]*extractors.
 	for _, src := range srcs {
 		srcurl := uu.Scheme + "://" + uu.Host + src.url
 		// skipping an extra HEAD request to the URL.
-		// size, err := request.Size(srcurl, u)
+		// size, err := option.Client.Size(srcurl, u)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -1,61 +1,48 @@
 package extractors
 
 import (
-	"net/url"
-	"strings"
-	"sync"
-
 	"github.com/pkg/errors"
 
-	"github.com/iawia002/lux/utils"
+	"github.com/iawia002/lux/request"
 )
 
-var lock sync.RWMutex
-var extractorMap = make(map[string]Extractor)
-
-// Register registers an Extractor.
-func Register(domain string, e Extractor) {
-	lock.Lock()
-	extractorMap[domain] = e
-	lock.Unlock()
-}
-
 // Extract is the main function to extract the data.
 func Extract(u string, option Options) ([]*Data, error) {
-	u = strings.TrimSpace(u)
-	var domain string
-
-	bilibiliShortLink := utils.MatchOneOf(u, `^(av|BV|ep)\w+`)
-	if len(bilibiliShortLink) > 1 {
-		bilibiliURL := map[string]string{
-			"av": "https://www.bilibili.com/video/",
-			"BV": "https://www.bilibili.com/video/",
-			"ep": "https://www.bilibili.com/bangumi/play/",
-		}
-		domain = "bilibili"
-		u = bilibiliURL[bilibiliShortLink[1]] + u
-	} else {
-		u, err := url.ParseRequestURI(u)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		if u.Host == "haokan.baidu.com" {
-			domain = "haokan"
-		} else if u.Host == "xhslink.com" {
-			domain = "xiaohongshu"
-		} else {
-			domain = utils.Domain(u.Host)
-		}
+	original := u
+	u, chain, err := Resolve(option.Client, u)
+	if err != nil {
+		return nil, err
 	}
-	extractor := extractorMap[domain]
-	if extractor == nil {
-		extractor = extractorMap[""]
+	if len(chain) > 0 && option.Client.Options().Debug {
+		printChain(chain, u)
+	}
+	m, err := Lookup(u)
+	if err != nil {
+		return nil, err
+	}
+	if m.Extractor == nil {
+		return nil, errors.Errorf("no extractor of %s", u)
+	}
+	u, domain, extractor := m.URL, m.Site, m.Extractor
+	authenticated, err := authenticate(extractor, domain, option, false)
+	if err != nil {
+		return nil, err
 	}
 	videos, err := extractor.Extract(u, option)
+	if err != nil && authenticated && (errors.Is(err, request.ErrUnauthorized) || errors.Is(err, request.ErrForbidden)) {
+		// the session has expired on the server side, log in again
+		if _, err = authenticate(extractor, domain, option, true); err != nil {
+			return nil, err
+		}
+		videos, err = extractor.Extract(u, option)
+	}
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	for _, v := range videos {
+		if len(chain) > 0 && v.OriginalURL == "" {
+			v.OriginalURL = original
+		}
 		v.FillUpStreamsData()
 	}
 	return videos, nil
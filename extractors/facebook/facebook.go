@@ -7,7 +7,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -25,7 +24,7 @@ func New() extractors.Extractor {
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
 	var err error
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -53,7 +52,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 		u := strings.ReplaceAll(matcher[1], "\\", "")
 
-		size, err := request.Size(u, url)
+		size, err := option.Client.Size(u, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -0,0 +1,69 @@
+package geekbang
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/request"
+)
+
+const (
+	loginAPI = "https://account.geekbang.org/account/ticket/login"
+	// sessionCookie is set by a successful login.
+	sessionCookie = "GCESS"
+)
+
+type loginResult struct {
+	Code  int `json:"code"`
+	Error struct {
+		Msg string `json:"msg"`
+	} `json:"error"`
+}
+
+// LoggedIn implements the extractors.Authenticator interface.
+func (e *extractor) LoggedIn(client *request.Client) bool {
+	for _, c := range client.Cookies("https://time.geekbang.org/") {
+		if c.Name == sessionCookie {
+			return true
+		}
+	}
+	return false
+}
+
+// Login implements the extractors.Authenticator interface, the username is the cellphone number.
+func (e *extractor) Login(client *request.Client, username, password string) error {
+	params, err := json.Marshal(map[string]interface{}{
+		"country":   86,
+		"cellphone": username,
+		"password":  password,
+		"captcha":   "",
+		"remember":  1,
+		"platform":  3,
+		"appid":     1,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	headers := map[string]string{
+		"Origin":       "https://account.geekbang.org",
+		"Referer":      "https://account.geekbang.org/signin",
+		"Content-Type": "application/json",
+	}
+	res, err := client.Request(http.MethodPost, loginAPI, strings.NewReader(string(params)), headers)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close() // nolint
+
+	var result loginResult
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return errors.WithStack(err)
+	}
+	if result.Code != 0 {
+		return errors.Errorf("geekbang login error: %s", result.Error.Msg)
+	}
+	return nil
+}
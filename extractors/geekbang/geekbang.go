@@ -14,7 +14,7 @@ import (
 )
 
 func init() {
-	extractors.Register("geekbang", New())
+	extractors.Register("geekbang", New(), extractors.CapabilityLogin)
 }
 
 type geekData struct {
@@ -55,14 +55,14 @@ type geekURLInfo struct {
 	Size int64
 }
 
-func geekM3u8(url string) ([]geekURLInfo, error) {
+func geekM3u8(client *request.Client, url string) ([]geekURLInfo, error) {
 	var (
 		data []geekURLInfo
 		temp geekURLInfo
 		size int64
 		err  error
 	)
-	urls, err := utils.M3u8URLs(url)
+	urls, err := utils.M3u8URLs(client, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -84,7 +84,7 @@ func New() extractors.Extractor {
 }
 
 // Extract is the main function to extract the data.
-func (e *extractor) Extract(url string, _ extractors.Options) ([]*extractors.Data, error) {
+func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
 	var err error
 	matches := utils.MatchOneOf(url, `https?://time.geekbang.org/course/detail/(\d+)-(\d+)`)
 	if matches == nil || len(matches) < 3 {
@@ -94,7 +94,7 @@ func (e *extractor) Extract(url string, _ extractors.Options) ([]*extractors.Dat
 	// Get video information
 	heanders := map[string]string{"Origin": "https://time.geekbang.org", "Content-Type": "application/json", "Referer": url}
 	params := strings.NewReader(fmt.Sprintf(`{"id": %q}`, matches[2]))
-	res, err := request.Request(http.MethodPost, "https://time.geekbang.org/serv/v1/article", params, heanders)
+	res, err := option.Client.Request(http.MethodPost, "https://time.geekbang.org/serv/v1/article", params, heanders)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -115,7 +115,7 @@ func (e *extractor) Extract(url string, _ extractors.Options) ([]*extractors.Dat
 
 	// Get video license token information
 	params = strings.NewReader("{\"source_type\":1,\"aid\":" + matches[2] + ",\"video_id\":\"" + data.Data.VideoID + "\"}")
-	res, err = request.Request(http.MethodPost, "https://time.geekbang.org/serv/v3/source_auth/video_play_auth", params, heanders)
+	res, err = option.Client.Request(http.MethodPost, "https://time.geekbang.org/serv/v3/source_auth/video_play_auth", params, heanders)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -132,7 +132,7 @@ func (e *extractor) Extract(url string, _ extractors.Options) ([]*extractors.Dat
 
 	// Get video playback information
 	heanders = map[string]string{"Accept-Encoding": ""}
-	res, err = request.Request(http.MethodGet, "http://ali.mantv.top/play/info?playAuth="+playAuth.Data.PlayAuth, nil, heanders)
+	res, err = option.Client.Request(http.MethodGet, "http://ali.mantv.top/play/info?playAuth="+playAuth.Data.PlayAuth, nil, heanders)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -148,7 +148,7 @@ func (e *extractor) Extract(url string, _ extractors.Options) ([]*extractors.Dat
 	streams := make(map[string]*extractors.Stream, len(playInfo.PlayInfoList.PlayInfo))
 
 	for _, media := range playInfo.PlayInfoList.PlayInfo {
-		m3u8URLs, err := geekM3u8(media.URL)
+		m3u8URLs, err := geekM3u8(option.Client, media.URL)
 
 		if err != nil {
 			return nil, errors.WithStack(err)
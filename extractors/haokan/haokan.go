@@ -6,12 +6,11 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("haokan", New())
+	extractors.RegisterRoutes("haokan", New(), extractors.Route{Domain: "haokan"}, extractors.Route{Host: "haokan.baidu.com"})
 }
 
 type extractor struct{}
@@ -23,7 +22,7 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -48,12 +47,12 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 	playurl := strings.Replace(urls[1], `\/`, `/`, -1)
 
-	size, err := request.Size(playurl, url)
+	size, err := option.Client.Size(playurl, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	_, ext, err := utils.GetNameAndExt(playurl)
+	_, ext, err := utils.GetNameAndExt(option.Client, playurl)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
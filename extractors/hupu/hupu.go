@@ -4,7 +4,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -20,7 +19,7 @@ func New() extractors.Extractor {
 }
 
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -41,7 +40,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(extractors.ErrURLParseFailed)
 	}
 
-	size, err := request.Size(videoUrl, url)
+	size, err := option.Client.Size(videoUrl, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
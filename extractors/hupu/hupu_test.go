@@ -22,7 +22,7 @@ func TestHupu(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			New().Extract(tt.args.URL, extractors.Options{})
+			New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 		})
 	}
 }
@@ -0,0 +1,110 @@
+package instagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/request"
+	"github.com/iawia002/lux/utils"
+)
+
+const (
+	homeURL  = "https://www.instagram.com/"
+	loginURL = "https://www.instagram.com/accounts/login/"
+	loginAPI = "https://www.instagram.com/accounts/login/ajax/"
+	// appID is the ID of the instagram web app, the API rejects the requests without it.
+	appID = "936619743392459"
+	// sessionCookie is set by a successful login, csrfCookie is set by the login page.
+	sessionCookie = "sessionid"
+	csrfCookie    = "csrftoken"
+)
+
+type loginResult struct {
+	Authenticated     bool   `json:"authenticated"`
+	User              bool   `json:"user"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	CheckpointURL     string `json:"checkpoint_url"`
+	Message           string `json:"message"`
+}
+
+// cookie returns the value of the cookie of instagram in the jar of the client.
+func cookie(client *request.Client, name string) string {
+	for _, c := range client.Cookies(homeURL) {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// LoggedIn implements the extractors.Authenticator interface.
+func (e *extractor) LoggedIn(client *request.Client) bool {
+	return cookie(client, sessionCookie) != ""
+}
+
+// Login implements the extractors.Authenticator interface, the username is the username, email address or
+// phone number of the account. The accounts with two-factor authentication can't log in.
+func (e *extractor) Login(client *request.Client, username, password string) error {
+	page, err := client.Get(loginURL, homeURL, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	token := cookie(client, csrfCookie)
+	if token == "" {
+		if m := utils.MatchOneOf(page, `"csrf_token":"(\w+)"`); m != nil {
+			token = m[1]
+		}
+	}
+	if token == "" {
+		return errors.New("instagram login error: no csrf token")
+	}
+
+	form := url.Values{
+		"username": {username},
+		// the password is sent as is, the version 0 of the browser password format isn't encrypted
+		"enc_password":  {fmt.Sprintf("#PWD_INSTAGRAM_BROWSER:0:%d:%s", time.Now().Unix(), password)},
+		"queryParams":   {"{}"},
+		"optIntoOneTap": {"false"},
+	}
+	headers := map[string]string{
+		"Referer":          loginURL,
+		"Content-Type":     "application/x-www-form-urlencoded",
+		"X-CSRFToken":      token,
+		"X-IG-App-ID":      appID,
+		"X-Requested-With": "XMLHttpRequest",
+	}
+	res, err := client.Request(http.MethodPost, loginAPI, strings.NewReader(form.Encode()), headers)
+	if err != nil {
+		var httpErr *request.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusBadRequest {
+			return errors.New("instagram login error: the login is rejected, it may need a check in the browser")
+		}
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close() // nolint
+
+	var result loginResult
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return errors.WithStack(err)
+	}
+	switch {
+	case result.TwoFactorRequired:
+		return errors.New("instagram login error: two-factor authentication is not supported, use the cookies instead")
+	case result.CheckpointURL != "":
+		return errors.Errorf("instagram login error: the login needs a check in the browser at %s", result.CheckpointURL)
+	case !result.User:
+		return errors.New("instagram login error: no such user")
+	case !result.Authenticated:
+		return errors.New("instagram login error: wrong password")
+	}
+	if !e.LoggedIn(client) {
+		return errors.New("instagram login error: no session cookie")
+	}
+	return nil
+}
@@ -3,12 +3,9 @@ package instagram
 import (
 	"encoding/json"
 	"fmt"
-	"net"
-	"net/http"
 	netURL "net/url"
 	"regexp"
 	"strings"
-	"time"
 
 	browser "github.com/EDDYCJY/fake-useragent"
 	"github.com/gocolly/colly/v2"
@@ -19,19 +16,8 @@ import (
 	"github.com/iawia002/lux/utils"
 )
 
-var client *http.Client
-
 func init() {
-	extractors.Register("instagram", New())
-	client = &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			Dial: (&net.Dialer{
-				Timeout: 5 * time.Second,
-			}).Dial,
-			TLSHandshakeTimeout: 5 * time.Second,
-		},
-	}
+	extractors.Register("instagram", New(), extractors.CapabilityImages, extractors.CapabilityLogin)
 }
 
 // sliderItemNode contains information about the Instagram post
@@ -65,13 +51,15 @@ func (s instagramPayload) isEmpty() bool {
 	return s.Media.ID == ""
 }
 
-func getPostWithCode(code string) ([]string, error) {
+// getPostWithCode fetches the media URLs of the post, the session in the cookie jar of the client is sent with
+// the request to see the posts of the private accounts that it follows.
+func getPostWithCode(client *request.Client, code string) ([]string, error) {
 	URL := fmt.Sprintf("https://www.instagram.com/p/%v/embed/captioned/", code)
 
 	var embeddedMediaImage string
 	var embedResponse = instagramPayload{}
 	collector := colly.NewCollector()
-	collector.SetClient(client)
+	collector.SetClient(client.HTTPClient())
 	var collectorErr error
 
 	collector.OnHTML("img.EmbeddedMediaImage", func(e *colly.HTMLElement) {
@@ -154,7 +142,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(err)
 	}
 
-	urls, err := getPostWithCode(shortCode)
+	urls, err := getPostWithCode(option.Client, shortCode)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -163,11 +151,11 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	var parts []*extractors.Part
 
 	for _, u := range urls {
-		_, ext, err := utils.GetNameAndExt(u)
+		_, ext, err := utils.GetNameAndExt(option.Client, u)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
-		fileSize, err := request.Size(u, url)
+		fileSize, err := option.Client.Size(u, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -12,7 +12,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -73,7 +72,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	id := r.FindSubmatch([]byte(finalURL))[2]
 	url2 := fmt.Sprintf("https://www.ixigua.com/%s", string(id))
 
-	body, err := request.Get(url2, url, headers)
+	body, err := option.Client.Get(url2, url, headers)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -13,7 +13,7 @@ import (
 )
 
 func init() {
-	extractors.Register("kuaishou", New())
+	extractors.Register("kuaishou", New(), extractors.CapabilityPlaylist)
 }
 
 type extractor struct{}
@@ -24,8 +24,8 @@ func New() extractors.Extractor {
 }
 
 // fetch url and get the cookie that write by server
-func fetchCookies(url string, headers map[string]string) (string, error) {
-	res, err := request.Request(http.MethodGet, url, nil, headers)
+func fetchCookies(client *request.Client, url string, headers map[string]string) (string, error) {
+	res, err := client.Request(http.MethodGet, url, nil, headers)
 	if err != nil {
 		return "", err
 	}
@@ -44,18 +44,23 @@ func fetchCookies(url string, headers map[string]string) (string, error) {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
+	if userID := utils.MatchOneOf(url, `kuaishou\.com/profile/([\w-]+)`); userID != nil {
+		// a profile is always the list of the videos of the user
+		return extractProfile(userID[1], option)
+	}
+
 	headers := map[string]string{
 		"User-Agent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0",
 	}
 
-	cookies, err := fetchCookies(url, headers)
+	cookies, err := fetchCookies(option.Client, url, headers)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
 	headers["Cookie"] = cookies
 
-	html, err := request.Get(url, url, headers)
+	html, err := option.Client.Get(url, url, headers)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -80,7 +85,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 		u := strings.ReplaceAll(matcher[1], `\u002F`, "/")
 
-		size, err := request.Size(u, url)
+		size, err := option.Client.Size(u, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -97,8 +102,14 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		}
 	}
 
+	var id string
+	if ids := utils.MatchOneOf(url, `/short-video/(\w+)`); ids != nil {
+		id = ids[1]
+	}
+
 	return []*extractors.Data{
 		{
+			ID:      id,
 			Site:    "快手 kuaishou.com",
 			Title:   title,
 			Type:    extractors.DataTypeVideo,
@@ -0,0 +1,118 @@
+package kuaishou
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+const graphqlAPI = "https://www.kuaishou.com/graphql"
+
+const profileQuery = `fragment feedContent on Feed {
+  photo {
+    id
+    caption
+    timestamp
+  }
+}
+
+query visionProfilePhotoList($pcursor: String, $userId: String, $page: String) {
+  visionProfilePhotoList(pcursor: $pcursor, userId: $userId, page: $page) {
+    result
+    pcursor
+    feeds {
+      ...feedContent
+    }
+  }
+}
+`
+
+// noMore is the cursor of the last page.
+const noMore = "no_more"
+
+type profileRequest struct {
+	OperationName string            `json:"operationName"`
+	Variables     map[string]string `json:"variables"`
+	Query         string            `json:"query"`
+}
+
+// {"data":{"visionProfilePhotoList":{"result":1,"pcursor":"1.7e+12","feeds":[{"photo":{"id":"3xabc","caption":"a"}}]}}}
+type profilePhotos struct {
+	Data struct {
+		VisionProfilePhotoList struct {
+			Result  int    `json:"result"`
+			Pcursor string `json:"pcursor"`
+			Feeds   []struct {
+				Photo struct {
+					ID      string `json:"id"`
+					Caption string `json:"caption"`
+				} `json:"photo"`
+			} `json:"feeds"`
+		} `json:"visionProfilePhotoList"`
+	} `json:"data"`
+}
+
+// extractProfile extracts the videos of the profile, eg: https://www.kuaishou.com/profile/{userId},
+// they are numbered from the newest one.
+func extractProfile(userID string, option extractors.Options) ([]*extractors.Data, error) {
+	profileURL := "https://www.kuaishou.com/profile/" + userID
+	headers := map[string]string{
+		"User-Agent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0",
+	}
+	cookies, err := fetchCookies(option.Client, profileURL, headers)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	headers["Cookie"] = cookies
+	headers["Referer"] = profileURL
+	headers["Content-Type"] = "application/json"
+
+	urls, err := extractors.Paginate(option, func(cursor string) (*extractors.Page, error) {
+		body, err := json.Marshal(profileRequest{
+			OperationName: "visionProfilePhotoList",
+			Variables:     map[string]string{"userId": userID, "pcursor": cursor, "page": "profile"},
+			Query:         profileQuery,
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res, err := option.Client.Request(http.MethodPost, graphqlAPI, strings.NewReader(string(body)), headers)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		defer res.Body.Close() // nolint
+
+		var photos profilePhotos
+		if err = json.NewDecoder(res.Body).Decode(&photos); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		list := photos.Data.VisionProfilePhotoList
+		if list.Result != 1 {
+			return nil, errors.Errorf("kuaishou profile %s error: result %d", userID, list.Result)
+		}
+		page := &extractors.Page{}
+		for _, feed := range list.Feeds {
+			page.URLs = append(page.URLs, "https://www.kuaishou.com/short-video/"+feed.Photo.ID)
+		}
+		if list.Pcursor != noMore {
+			page.Next = list.Pcursor
+		}
+		return page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &extractor{}
+	return extractors.ExtractItems(urls, option, func(url string) *extractors.Data {
+		data, err := e.Extract(url, option)
+		if err != nil {
+			return extractors.EmptyData(url, err)
+		}
+		return data[0]
+	}), nil
+}
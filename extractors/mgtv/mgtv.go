@@ -59,15 +59,15 @@ type mgtvPm2Data struct {
 	} `json:"data"`
 }
 
-func mgtvM3u8(url string) ([]mgtvURLInfo, int64, error) {
+func mgtvM3u8(client *request.Client, url string) ([]mgtvURLInfo, int64, error) {
 	var data []mgtvURLInfo
 	var temp mgtvURLInfo
 	var size, totalSize int64
-	urls, err := utils.M3u8URLs(url)
+	urls, err := utils.M3u8URLs(client, url)
 	if err != nil {
 		return nil, 0, err
 	}
-	m3u8String, err := request.Get(url, url, nil)
+	m3u8String, err := client.Get(url, url, nil)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -109,7 +109,7 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -132,7 +132,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		"Cookie": "PM_CHKID=1",
 	}
 	clit := fmt.Sprintf("clit=%d", time.Now().Unix()/1000)
-	pm2DataString, err := request.Get(
+	pm2DataString, err := option.Client.Get(
 		fmt.Sprintf(
 			"https://pcweb.api.mgtv.com/player/video?video_id=%s&tk2=%s",
 			vid[1],
@@ -151,7 +151,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(err)
 	}
 
-	dataString, err := request.Get(
+	dataString, err := option.Client.Get(
 		fmt.Sprintf(
 			"https://pcweb.api.mgtv.com/player/getSource?video_id=%s&tk2=%s&pm2=%s",
 			vid[1], encodeTk2(clit), pm2.Data.Atc.Pm2,
@@ -179,7 +179,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		}
 		// real download address
 		addr = mgtvVideoAddr{}
-		addrInfo, err := request.GetByte(mgtvData.Data.StreamDomain[0]+stream.URL, url, headers)
+		addrInfo, err := option.Client.GetByte(mgtvData.Data.StreamDomain[0]+stream.URL, url, headers)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -187,7 +187,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 			return nil, errors.WithStack(err)
 		}
 
-		m3u8URLs, totalSize, err := mgtvM3u8(addr.Info)
+		m3u8URLs, totalSize, err := mgtvM3u8(option.Client, addr.Info)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
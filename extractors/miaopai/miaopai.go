@@ -9,7 +9,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -58,7 +57,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	randomString := getRandomString(10)
 
 	var data miaopaiData
-	jsonString, err := request.Get(
+	jsonString, err := option.Client.Get(
 		fmt.Sprintf("https://n.miaopai.com/api/aj_media/info.json?smid=%s&appid=530&_cb=_jsonp%s", id, randomString),
 		url, nil,
 	)
@@ -77,7 +76,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	}
 
 	realURL := data.Data.MetaData[0].URLs.M
-	size, err := request.Size(realURL, url)
+	size, err := option.Client.Size(realURL, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -7,7 +7,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -30,7 +29,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.New("invalid url for netease music")
 	}
 
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -50,7 +49,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	}
 	realURL, _ := netURL.QueryUnescape(realURLs[1])
 
-	size, err := request.Size(realURL, url)
+	size, err := option.Client.Size(realURL, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
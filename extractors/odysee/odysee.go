@@ -9,7 +9,6 @@ import (
 	"regexp"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/pkg/errors"
 )
 
@@ -33,7 +32,7 @@ func New() extractors.Extractor {
 }
 
 func (e *extractor) Extract(u string, option extractors.Options) ([]*extractors.Data, error) {
-	res, err := request.Request(http.MethodGet, u, nil, nil)
+	res, err := option.Client.Request(http.MethodGet, u, nil, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -67,7 +66,7 @@ func (e *extractor) Extract(u string, option extractors.Options) ([]*extractors.
 	}
 
 	streams := make(map[string]*extractors.Stream, 1)
-	size, err := request.Size(resData.ContentURL, u)
+	size, err := option.Client.Size(resData.ContentURL, u)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
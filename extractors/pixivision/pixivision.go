@@ -5,12 +5,11 @@ import (
 
 	"github.com/iawia002/lux/extractors"
 	"github.com/iawia002/lux/parser"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("pixivision", New())
+	extractors.Register("pixivision", New(), extractors.CapabilityImages)
 }
 
 type extractor struct{}
@@ -22,7 +21,7 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -33,11 +32,11 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 	parts := make([]*extractors.Part, 0, len(urls))
 	for _, u := range urls {
-		_, ext, err := utils.GetNameAndExt(u)
+		_, ext, err := utils.GetNameAndExt(option.Client, u)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
-		size, err := request.Size(u, url)
+		size, err := option.Client.Size(u, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -0,0 +1,81 @@
+package extractors
+
+import (
+	"slices"
+
+	"github.com/iawia002/lux/utils"
+)
+
+// Page is a page of a paginated playlist, eg: a page of the videos of a channel.
+type Page struct {
+	// URLs are the items of the page, newest first.
+	URLs []string
+	// Next is the cursor of the next page, it is empty on the last page.
+	Next string
+}
+
+// Paginate fetches the pages of a playlist, the cursor of the first page is empty, and returns the URLs of
+// the items selected by the Items, ItemStart and ItemEnd options. The items are numbered newest first, the
+// pages after the last selected item are not fetched, unless OldestFirst is set, which needs all the pages.
+func Paginate(option Options, fetch func(cursor string) (*Page, error)) ([]string, error) {
+	limit := 0
+	if !option.OldestFirst {
+		switch {
+		case option.Items != "":
+			for _, item := range utils.NeedDownloadList(option.Items, 0, 0, 0) {
+				limit = max(limit, item)
+			}
+		case option.ItemEnd > 0:
+			limit = max(option.ItemStart, option.ItemEnd)
+		}
+	}
+
+	var (
+		urls   []string
+		cursor string
+	)
+	for {
+		page, err := fetch(cursor)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, page.URLs...)
+		// an empty page or the same cursor again would never end
+		if page.Next == "" || page.Next == cursor || len(page.URLs) == 0 || (limit > 0 && len(urls) >= limit) {
+			break
+		}
+		cursor = page.Next
+	}
+
+	if option.OldestFirst {
+		slices.Reverse(urls)
+	}
+	items := utils.NeedDownloadList(option.Items, option.ItemStart, option.ItemEnd, len(urls))
+	selected := make([]string, 0, len(items))
+	for _, item := range items {
+		if item >= 1 && item <= len(urls) {
+			selected = append(selected, urls[item-1])
+		}
+	}
+	return selected, nil
+}
+
+// ExtractItems extracts the items of a playlist by the extract function in ThreadNumber goroutines, the items
+// skipped by SkipItem are left out. The data keeps the order of the URLs, and the URL of each data is its URL.
+func ExtractItems(urls []string, option Options, extract func(url string) *Data) []*Data {
+	if option.SkipItem != nil {
+		urls = slices.DeleteFunc(slices.Clone(urls), option.SkipItem)
+	}
+	data := make([]*Data, len(urls))
+	wgp := utils.NewWaitGroupPool(option.ThreadNumber)
+	for i, u := range urls {
+		wgp.Add()
+		go func(i int, u string) {
+			defer wgp.Done()
+			data[i] = extract(u)
+			data[i].URL = u
+		}(i, u)
+	}
+	wgp.Wait()
+	return data
+}
@@ -0,0 +1,59 @@
+package extractors
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// pages serves 3 pages of 2 items, the items are numbered from the newest one.
+func pages(fetched *int) func(cursor string) (*Page, error) {
+	return func(cursor string) (*Page, error) {
+		*fetched++
+		n := 0
+		if cursor != "" {
+			n, _ = strconv.Atoi(cursor)
+		}
+		page := &Page{URLs: []string{strconv.Itoa(n*2 + 1), strconv.Itoa(n*2 + 2)}}
+		if n < 2 {
+			page.Next = strconv.Itoa(n + 1)
+		}
+		return page, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      Options
+		want        []string
+		wantFetched int
+	}{
+		{name: "all", want: []string{"1", "2", "3", "4", "5", "6"}, wantFetched: 3},
+		{name: "end", option: Options{ItemStart: 2, ItemEnd: 3}, want: []string{"2", "3"}, wantFetched: 2},
+		{name: "items", option: Options{Items: "1,2"}, want: []string{"1", "2"}, wantFetched: 1},
+		{name: "out of range", option: Options{Items: "5-8"}, want: []string{"5", "6"}, wantFetched: 3},
+		{name: "oldest first", option: Options{OldestFirst: true, ItemEnd: 2}, want: []string{"6", "5"}, wantFetched: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetched int
+			got, err := Paginate(tt.option, pages(&fetched))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Paginate() = %v, want %v", got, tt.want)
+			}
+			if fetched != tt.wantFetched {
+				t.Errorf("%d pages are fetched, want %d", fetched, tt.wantFetched)
+			}
+		})
+	}
+
+	_, err := Paginate(Options{}, func(string) (*Page, error) { return nil, errors.New("fetch error") })
+	if err == nil {
+		t.Error("Paginate() should return the fetch error")
+	}
+}
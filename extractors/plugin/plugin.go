@@ -0,0 +1,127 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/config"
+	"github.com/iawia002/lux/extractors"
+)
+
+// Request is written to the stdin of the plugin.
+type Request struct {
+	URL     string             `json:"url"`
+	Options extractors.Options `json:"options"`
+}
+
+// data is the extracted data written by the plugin, the error is a message.
+type data struct {
+	*extractors.Data
+	Err string `json:"err"`
+}
+
+type extractor struct {
+	plugin config.Plugin
+}
+
+// New returns an extractor that runs the plugin.
+func New(plugin config.Plugin) extractors.Extractor {
+	return &extractor{plugin: plugin}
+}
+
+// priority makes the plugins take precedence over the built-in extractors.
+const priority = 100
+
+// Register registers the plugin as the extractor of its domains and URL patterns,
+// a domain with a dot is a host, eg: example.io, otherwise it is a site name, eg: example.
+func Register(plugin config.Plugin) error {
+	if plugin.Command == "" {
+		return errors.Errorf("plugin %s has no command", plugin.Name)
+	}
+	if len(plugin.Domains) == 0 && len(plugin.Patterns) == 0 {
+		return errors.Errorf("plugin %s handles no domains or URL patterns", plugin.Name)
+	}
+	routes := make([]extractors.Route, 0, len(plugin.Patterns)+len(plugin.Domains))
+	for _, p := range plugin.Patterns {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return errors.Wrapf(err, "invalid URL pattern of plugin %s", plugin.Name)
+		}
+		routes = append(routes, extractors.Route{Pattern: pattern, Priority: priority})
+	}
+	for _, domain := range plugin.Domains {
+		if strings.Contains(domain, ".") {
+			routes = append(routes, extractors.Route{Host: strings.ToLower(domain), Priority: priority})
+		} else {
+			routes = append(routes, extractors.Route{Domain: domain, Priority: priority})
+		}
+	}
+	extractors.RegisterRoutes(plugin.Name, New(plugin), routes...)
+	return nil
+}
+
+// Extract is the main function to extract the data.
+func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
+	input, err := json.Marshal(Request{URL: url, Options: option})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ctx := option.Client.Context()
+	if e.plugin.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.plugin.Timeout)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.plugin.Command, e.plugin.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// don't wait for the children of a killed plugin that still hold its output
+	cmd.WaitDelay = time.Second
+	cmd.Env = os.Environ()
+	// the plugin sends its requests through the same proxy
+	if proxy := option.Client.Options().Proxy; proxy != "" {
+		cmd.Env = append(cmd.Env, "HTTP_PROXY="+proxy, "HTTPS_PROXY="+proxy, "ALL_PROXY="+proxy)
+	}
+	if err = cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, errors.Errorf("plugin %s error: %v: %s", e.plugin.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parse(stdout.Bytes(), e.plugin.Name)
+}
+
+// parse parses the output of the plugin, the site of the data is the plugin name if it is empty.
+func parse(output []byte, name string) ([]*extractors.Data, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(output, &items); err != nil {
+		return nil, errors.Wrapf(err, "invalid output of plugin %s", name)
+	}
+	result := make([]*extractors.Data, 0, len(items))
+	for _, item := range items {
+		d := data{Data: &extractors.Data{}}
+		if err := json.Unmarshal(item, &d); err != nil {
+			return nil, errors.Wrapf(err, "invalid output of plugin %s", name)
+		}
+		if d.Err != "" {
+			d.Data.Err = errors.New(d.Err)
+		}
+		if d.Site == "" {
+			d.Site = name
+		}
+		result = append(result, d.Data)
+	}
+	return result, nil
+}
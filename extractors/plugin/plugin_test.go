@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/iawia002/lux/config"
+	"github.com/iawia002/lux/extractors"
+)
+
+// script writes a shell script plugin and returns its path.
+func script(t *testing.T, content string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugins of the tests are shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		want    []*extractors.Data
+		wantErr bool
+	}{
+		{
+			name: "normal test",
+			// echoes the URL and the cookie of the request
+			script: `input=$(cat)
+url=$(echo "$input" | sed 's/.*"url":"\([^"]*\)".*/\1/')
+cookie=$(echo "$input" | sed 's/.*"cookie":"\([^"]*\)".*/\1/')
+echo '[{"url":"'$url'","title":"'$cookie'","type":"video","streams":{"default":{"parts":[{"url":"https://example.com/1.mp4","size":1,"ext":"mp4"}]}}},{"url":"'$url'","err":"not found"}]'
+`,
+			want: []*extractors.Data{
+				{
+					URL:   "https://example.com/v/1",
+					Site:  "example",
+					Title: "a=b",
+					Type:  extractors.DataTypeVideo,
+					Streams: map[string]*extractors.Stream{
+						"default": {Parts: []*extractors.Part{{URL: "https://example.com/1.mp4", Size: 1, Ext: "mp4"}}},
+					},
+				},
+				{URL: "https://example.com/v/1", Site: "example"},
+			},
+		},
+		{
+			name:    "exit error",
+			script:  "echo 'unsupported URL' >&2\nexit 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid output",
+			script:  "echo 'not json'\n",
+			wantErr: true,
+		},
+		{
+			name:    "timeout",
+			script:  "sleep 5\n",
+			timeout: 100 * time.Millisecond,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(config.Plugin{Name: "example", Command: script(t, tt.script), Timeout: tt.timeout})
+			got, err := e.Extract("https://example.com/v/1", extractors.Options{Cookie: "a=b"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Extract() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Extract() returns %d items, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].URL != want.URL || got[i].Site != want.Site || got[i].Title != want.Title || got[i].Type != want.Type {
+					t.Errorf("Extract()[%d] = %+v, want %+v", i, got[i], want)
+				}
+				if len(got[i].Streams) != len(want.Streams) {
+					t.Errorf("Extract()[%d] has %d streams, want %d", i, len(got[i].Streams), len(want.Streams))
+				}
+			}
+			if got[1].Err == nil || got[1].Err.Error() != "not found" {
+				t.Errorf("Extract()[1].Err = %v, want not found", got[1].Err)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	path := script(t, `cat > /dev/null
+echo '[{"url":"https://v.example.io/1","title":"pattern"}]'
+`)
+	if err := Register(config.Plugin{Name: "example", Command: path, Patterns: []string{`^https?://v\.example\.io/`}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := extractors.Extract("https://v.example.io/1", extractors.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || data[0].Title != "pattern" {
+		t.Errorf("Extract() = %+v, want the data of the plugin", data)
+	}
+
+	if err = Register(config.Plugin{Name: "nothing", Command: path}); err == nil {
+		t.Error("a plugin without domains and patterns should not be registered")
+	}
+	if err = Register(config.Plugin{Name: "invalid", Command: path, Patterns: []string{"("}}); err == nil {
+		t.Error("a plugin with an invalid pattern should not be registered")
+	}
+}
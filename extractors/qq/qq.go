@@ -54,8 +54,8 @@ type qqKeyInfo struct {
 
 const qqPlayerVersion string = "3.2.19.333"
 
-func getVinfo(vid, defn, refer string) (qqVideoInfo, error) {
-	html, err := request.Get(
+func getVinfo(client *request.Client, vid, defn, refer string) (qqVideoInfo, error) {
+	html, err := client.Get(
 		fmt.Sprintf(
 			"http://vv.video.qq.com/getinfo?otype=json&platform=11&defnpayver=1&appver=%s&defn=%s&vid=%s",
 			qqPlayerVersion, defn, vid,
@@ -76,7 +76,7 @@ func getVinfo(vid, defn, refer string) (qqVideoInfo, error) {
 	return data, nil
 }
 
-func genStreams(vid, cdn string, data qqVideoInfo) (map[string]*extractors.Stream, error) {
+func genStreams(client *request.Client, vid, cdn string, data qqVideoInfo) (map[string]*extractors.Stream, error) {
 	streams := make(map[string]*extractors.Stream)
 	var vkey string
 	// number of fragments
@@ -99,7 +99,7 @@ func genStreams(vid, cdn string, data qqVideoInfo) (map[string]*extractors.Strea
 				clips = 1
 			}
 		} else {
-			tmpData, err := getVinfo(vid, fi.Name, cdn)
+			tmpData, err := getVinfo(client, vid, fi.Name, cdn)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -128,7 +128,7 @@ func genStreams(vid, cdn string, data qqVideoInfo) (map[string]*extractors.Strea
 				}
 			}
 			filename = strings.Join(fns, ".")
-			html, err := request.Get(
+			html, err := client.Get(
 				fmt.Sprintf(
 					"http://vv.video.qq.com/getkey?otype=json&platform=11&appver=%s&filename=%s&format=%d&vid=%s",
 					qqPlayerVersion, filename, fi.ID, vid,
@@ -153,7 +153,7 @@ func genStreams(vid, cdn string, data qqVideoInfo) (map[string]*extractors.Strea
 				vkey = data.Vl.Vi[0].Fvkey
 			}
 			realURL := fmt.Sprintf("%s%s?vkey=%s", cdn, filename, vkey)
-			size, err := request.Size(realURL, cdn)
+			size, err := client.Size(realURL, cdn)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -190,7 +190,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	vid := vids[1]
 
 	if len(vid) != 11 {
-		u, err := request.Get(url, url, nil)
+		u, err := option.Client.Get(url, url, nil)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -204,7 +204,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		vid = vids[1]
 	}
 
-	data, err := getVinfo(vid, "shd", url)
+	data, err := getVinfo(option.Client, vid, "shd", url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -214,7 +214,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.New(data.Msg)
 	}
 	cdn := data.Vl.Vi[0].Ul.UI[0].URL
-	streams, err := genStreams(vid, cdn, data)
+	streams, err := genStreams(option.Client, vid, cdn, data)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -7,12 +7,11 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("reddit", New())
+	extractors.Register("reddit", New(), extractors.CapabilityImages)
 }
 
 const (
@@ -39,7 +38,7 @@ func New() extractors.Extractor {
 }
 
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, referer, nil)
+	html, err := option.Client.Get(url, referer, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -56,7 +55,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		}
 
 		audioURL := fmt.Sprintf("%s%s%s", redditMP4API, mp4URL, audioURLPart)
-		size, err := request.Size(audioURL, referer)
+		size, err := option.Client.Size(audioURL, referer)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -69,7 +68,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		streams := make(map[string]*extractors.Stream, len(resMap))
 		for res, urlParts := range resMap {
 			resURL := fmt.Sprintf("%s%s%s", redditMP4API, mp4URL, urlParts)
-			size, err := request.Size(resURL, referer)
+			size, err := option.Client.Size(resURL, referer)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -102,14 +101,14 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		var size int64
 		if utils.MatchOneOf(html, `content":"https:\/\/i.redd.it\/(.+?)","type":"image"`) != nil {
 			imgURL = redditIMGAPI + utils.MatchOneOf(html, `content":"https:\/\/i.redd.it\/(.+?)","type":"image"`)[1]
-			size, err = request.Size(imgURL, referer)
+			size, err = option.Client.Size(imgURL, referer)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
 		} else {
 			imgURL = utils.MatchOneOf(html, `content":"(.+?)","type":"image"`)[1]
 			imgURL = strings.ReplaceAll(imgURL, "auto=webp\\u0026s", "auto=webp&s")
-			size, err = request.Size(imgURL, referer)
+			size, err = option.Client.Size(imgURL, referer)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -144,7 +143,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		gifURL = strings.ReplaceAll(gifURL, "&amp;", "&")
 		gifURL = strings.ReplaceAll(gifURL, "\"", "")
 
-		size, err := request.Size(gifURL, "reddit.com")
+		size, err := option.Client.Size(gifURL, "reddit.com")
 		if err != nil {
 			return nil, errors.New("can't get video size")
 		}
@@ -0,0 +1,322 @@
+package extractors
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/utils"
+)
+
+// Route matches the URLs of a site, a URL matches the route if it matches all the non-empty fields.
+type Route struct {
+	// Domain matches the second-level name of the host, eg: "bilibili" matches www.bilibili.com and bilibili.tv.
+	Domain string
+	// Host matches the host and its subdomains, eg: "haokan.baidu.com".
+	Host string
+	// Pattern matches the whole URL, eg: `^https?://www\.bilibili\.com/festival/`.
+	Pattern *regexp.Regexp
+	// Priority decides the route if several routes match a URL, the highest wins.
+	// Routes with the same priority are ordered by specificity: a host with a pattern, a longer host,
+	// a pattern and then a domain.
+	Priority int
+}
+
+// String returns the description of the route, eg: host:haokan.baidu.com.
+func (r Route) String() string {
+	var parts []string
+	if r.Domain != "" {
+		parts = append(parts, "domain:"+r.Domain)
+	}
+	if r.Host != "" {
+		parts = append(parts, "host:"+r.Host)
+	}
+	if r.Pattern != nil {
+		parts = append(parts, "pattern:"+r.Pattern.String())
+	}
+	s := strings.Join(parts, " ")
+	if r.Priority != 0 {
+		s += fmt.Sprintf(" priority:%d", r.Priority)
+	}
+	return s
+}
+
+func (r Route) match(u *url.URL, raw string) bool {
+	host := strings.ToLower(u.Hostname())
+	if r.Domain != "" && utils.Domain(host) != r.Domain {
+		return false
+	}
+	if r.Host != "" && host != r.Host && !strings.HasSuffix(host, "."+r.Host) {
+		return false
+	}
+	if r.Pattern != nil && !r.Pattern.MatchString(raw) {
+		return false
+	}
+	return r.Domain != "" || r.Host != "" || r.Pattern != nil
+}
+
+// specificity ranks the routes with the same priority.
+func (r Route) specificity() int {
+	switch {
+	case r.Host != "" && r.Pattern != nil:
+		return 3000 + len(r.Host)
+	case r.Host != "":
+		return 2000 + len(r.Host)
+	case r.Pattern != nil:
+		return 1000
+	}
+	return 0
+}
+
+// key identifies the routes that always match the same URLs.
+func (r Route) key() string {
+	pattern := ""
+	if r.Pattern != nil {
+		pattern = r.Pattern.String()
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d", r.Domain, r.Host, pattern, r.Priority)
+}
+
+// ShortID expands the IDs like "BV1xx411c7mD" to the URLs of a site.
+type ShortID struct {
+	// Pattern matches the IDs.
+	Pattern *regexp.Regexp
+	// Format is the URL format of the ID, eg: "https://www.bilibili.com/video/%s".
+	Format string
+}
+
+// Capability is a feature that an extractor supports besides extracting a single video.
+type Capability string
+
+const (
+	// CapabilityPlaylist indicates the extractor extracts playlists with the Playlist option.
+	CapabilityPlaylist Capability = "playlist"
+	// CapabilityCaptions indicates the extractor extracts captions, eg: subtitles and danmaku.
+	CapabilityCaptions Capability = "captions"
+	// CapabilityLogin indicates the extractor logs in with the username and password.
+	CapabilityLogin Capability = "login"
+	// CapabilityLive indicates the extractor extracts live streams.
+	CapabilityLive Capability = "live"
+	// CapabilityImages indicates the extractor extracts images.
+	CapabilityImages Capability = "images"
+	// CapabilityAudio indicates the extractor extracts audio.
+	CapabilityAudio Capability = "audio"
+)
+
+type route struct {
+	Route
+	site      string
+	extractor Extractor
+}
+
+type shortID struct {
+	ShortID
+	site string
+}
+
+var (
+	lock     sync.RWMutex
+	routes   []route
+	shortIDs []shortID
+	// capabilities of the sites, the universal extractor is the empty site
+	capabilities = make(map[string][]Capability)
+	// universal extracts the URLs that no route matches
+	universal Extractor
+)
+
+// Register registers an Extractor of the domain and the capabilities it supports, eg: "bilibili" for www.bilibili.com,
+// the empty domain registers the universal extractor, a nil Extractor removes the routes of the domain.
+func Register(domain string, e Extractor, caps ...Capability) {
+	lock.Lock()
+	if e == nil {
+		filtered := routes[:0]
+		for _, r := range routes {
+			if r.site != domain {
+				filtered = append(filtered, r)
+			}
+		}
+		routes = filtered
+		delete(capabilities, domain)
+		if domain == "" {
+			universal = nil
+		}
+		lock.Unlock()
+		return
+	}
+	for _, c := range caps {
+		if !hasCapability(capabilities[domain], c) {
+			capabilities[domain] = append(capabilities[domain], c)
+		}
+	}
+	if domain == "" {
+		universal = e
+		lock.Unlock()
+		return
+	}
+	lock.Unlock()
+	RegisterRoutes(domain, e, Route{Domain: domain})
+}
+
+func hasCapability(caps []Capability, c Capability) bool {
+	for _, x := range caps {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterRoutes registers an Extractor of the site, it extracts the URLs that match any of the routes.
+func RegisterRoutes(site string, e Extractor, rs ...Route) {
+	lock.Lock()
+	defer lock.Unlock()
+next:
+	for _, r := range rs {
+		for i := range routes {
+			// registering the same route again replaces the extractor
+			if routes[i].site == site && routes[i].key() == r.key() {
+				routes[i].extractor = e
+				continue next
+			}
+		}
+		routes = append(routes, route{Route: r, site: site, extractor: e})
+	}
+}
+
+// RegisterShortID registers a short ID scheme of the site.
+func RegisterShortID(site string, s ShortID) {
+	lock.Lock()
+	shortIDs = append(shortIDs, shortID{ShortID: s, site: site})
+	lock.Unlock()
+}
+
+// Conflicts returns an error if different sites have registered the same route or short ID pattern,
+// it should be checked once all the extractors are registered.
+func Conflicts() error {
+	lock.RLock()
+	defer lock.RUnlock()
+	var conflicts []string
+	seen := make(map[string]string)
+	for _, r := range routes {
+		if site, ok := seen[r.key()]; ok && site != r.site {
+			conflicts = append(conflicts, fmt.Sprintf("%s and %s both register the route %s", site, r.site, r.Route))
+			continue
+		}
+		seen[r.key()] = r.site
+	}
+	seenIDs := make(map[string]string)
+	for _, s := range shortIDs {
+		if site, ok := seenIDs[s.Pattern.String()]; ok && site != s.site {
+			conflicts = append(conflicts, fmt.Sprintf("%s and %s both register the short ID %s", site, s.site, s.Pattern))
+			continue
+		}
+		seenIDs[s.Pattern.String()] = s.site
+	}
+	if len(conflicts) > 0 {
+		return errors.Errorf("extractor conflicts:\n%s", strings.Join(conflicts, "\n"))
+	}
+	return nil
+}
+
+// Site is the information of a registered site.
+type Site struct {
+	// Name is the name of the site, it is empty for the universal extractor.
+	Name         string
+	Routes       []Route
+	ShortIDs     []ShortID
+	Capabilities []Capability
+}
+
+// Sites returns the registered sites ordered by name, the universal extractor comes first if it is registered.
+func Sites() []Site {
+	lock.RLock()
+	defer lock.RUnlock()
+	index := make(map[string]int)
+	var sites []Site
+	site := func(name string) *Site {
+		i, ok := index[name]
+		if !ok {
+			i = len(sites)
+			index[name] = i
+			sites = append(sites, Site{Name: name, Capabilities: capabilities[name]})
+		}
+		return &sites[i]
+	}
+	if universal != nil {
+		site("")
+	}
+	for _, r := range routes {
+		s := site(r.site)
+		s.Routes = append(s.Routes, r.Route)
+	}
+	for _, id := range shortIDs {
+		s := site(id.site)
+		s.ShortIDs = append(s.ShortIDs, id.ShortID)
+	}
+	sort.SliceStable(sites, func(i, j int) bool {
+		return sites[i].Name < sites[j].Name
+	})
+	return sites
+}
+
+// Match is the extractor that a URL is routed to.
+type Match struct {
+	// URL is the input, the short ID is expanded.
+	URL string
+	// Site is the name of the site, it is empty if no route matches.
+	Site string
+	// Route is the matched route.
+	Route Route
+	// Capabilities are the capabilities of the site.
+	Capabilities []Capability
+	Extractor    Extractor
+}
+
+// Lookup expands the short ID and returns the extractor of the URL, the universal extractor is used if no route matches.
+func Lookup(input string) (*Match, error) {
+	input = strings.TrimSpace(input)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	m := &Match{URL: input, Extractor: universal}
+	var expanded []shortID
+	for _, s := range shortIDs {
+		if s.Pattern.MatchString(input) {
+			expanded = append(expanded, s)
+		}
+	}
+	switch len(expanded) {
+	case 0:
+	case 1:
+		m.URL = fmt.Sprintf(expanded[0].Format, input)
+	default:
+		return nil, errors.Errorf("%s matches the short IDs of both %s and %s", input, expanded[0].site, expanded[1].site)
+	}
+
+	u, err := url.ParseRequestURI(m.URL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var best *route
+	for i, r := range routes {
+		if !r.match(u, m.URL) {
+			continue
+		}
+		if best == nil || r.Priority > best.Priority ||
+			(r.Priority == best.Priority && r.specificity() > best.specificity()) {
+			best = &routes[i]
+		}
+	}
+	if best != nil {
+		m.Site = best.site
+		m.Route = best.Route
+		m.Extractor = best.extractor
+	}
+	m.Capabilities = capabilities[m.Site]
+	return m, nil
+}
@@ -0,0 +1,139 @@
+package extractors
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type fakeExtractor struct {
+	name string
+}
+
+func (e *fakeExtractor) Extract(url string, _ Options) ([]*Data, error) {
+	return []*Data{{URL: url, Site: e.name}}, nil
+}
+
+func TestLookup(t *testing.T) {
+	defer func(r []route, s []shortID, u Extractor, c map[string][]Capability) {
+		routes, shortIDs, universal, capabilities = r, s, u, c
+	}(routes, shortIDs, universal, capabilities)
+	routes, shortIDs, capabilities = nil, nil, make(map[string][]Capability)
+
+	Register("", &fakeExtractor{name: "universal"})
+	Register("example", &fakeExtractor{name: "example"}, CapabilityPlaylist)
+	RegisterRoutes("sub", &fakeExtractor{name: "sub"}, Route{Host: "sub.example.com"})
+	RegisterRoutes("live", &fakeExtractor{name: "live"}, Route{Host: "example.com", Pattern: regexp.MustCompile(`/live/`)})
+	RegisterRoutes("plugin", &fakeExtractor{name: "plugin"}, Route{Pattern: regexp.MustCompile(`/plugin/`), Priority: 100})
+	RegisterShortID("example", ShortID{Pattern: regexp.MustCompile(`^ex\d+$`), Format: "https://www.example.com/v/%s"})
+
+	tests := []struct {
+		name     string
+		input    string
+		wantURL  string
+		wantSite string
+		wantErr  bool
+	}{
+		{name: "domain", input: "https://www.example.net/v/1", wantSite: "example"},
+		{name: "longer host", input: "https://a.sub.example.com/v/1", wantSite: "sub"},
+		{name: "host with pattern", input: "https://sub.example.com/live/1", wantSite: "live"},
+		{name: "priority", input: "https://sub.example.com/live/plugin/1", wantSite: "plugin"},
+		{name: "host suffix only", input: "https://notsub.example.com/v/1", wantSite: "example"},
+		{name: "short ID", input: " ex123 ", wantURL: "https://www.example.com/v/ex123", wantSite: "example"},
+		{name: "universal", input: "https://other.org/a.mp4", wantSite: ""},
+		{name: "invalid URL", input: "not a url", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Lookup(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			wantURL := tt.wantURL
+			if wantURL == "" {
+				wantURL = tt.input
+			}
+			if m.URL != wantURL {
+				t.Errorf("Lookup().URL = %q, want %q", m.URL, wantURL)
+			}
+			wantExtractor := tt.wantSite
+			if wantExtractor == "" {
+				wantExtractor = "universal"
+			}
+			if m.Site != tt.wantSite || m.Extractor.(*fakeExtractor).name != wantExtractor {
+				t.Errorf("Lookup() = %s (%s), want %s", m.Site, m.Route, tt.wantSite)
+			}
+			if wantCaps := tt.wantSite == "example"; (len(m.Capabilities) > 0) != wantCaps {
+				t.Errorf("Lookup().Capabilities = %v", m.Capabilities)
+			}
+		})
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	defer func(r []route, s []shortID) {
+		routes, shortIDs = r, s
+	}(routes, shortIDs)
+	routes, shortIDs = nil, nil
+
+	e := &fakeExtractor{}
+	Register("a", e)
+	RegisterRoutes("a", e, Route{Host: "a.com"}, Route{Host: "a.com"})
+	RegisterRoutes("b", e, Route{Host: "a.com", Priority: 1})
+	RegisterShortID("a", ShortID{Pattern: regexp.MustCompile(`^a\d+`)})
+	if err := Conflicts(); err != nil {
+		t.Fatalf("unexpected conflicts: %v", err)
+	}
+
+	RegisterRoutes("c", e, Route{Host: "a.com"})
+	if err := Conflicts(); err == nil {
+		t.Error("the same host of different sites should conflict")
+	}
+	routes = routes[:len(routes)-1]
+
+	RegisterShortID("c", ShortID{Pattern: regexp.MustCompile(`^a\d+`)})
+	if err := Conflicts(); err == nil {
+		t.Error("the same short ID of different sites should conflict")
+	}
+	if _, err := Lookup("a1"); err == nil {
+		t.Error("an ambiguous short ID should fail")
+	}
+}
+
+func TestSites(t *testing.T) {
+	defer func(r []route, s []shortID, u Extractor, c map[string][]Capability) {
+		routes, shortIDs, universal, capabilities = r, s, u, c
+	}(routes, shortIDs, universal, capabilities)
+	routes, shortIDs, universal, capabilities = nil, nil, nil, make(map[string][]Capability)
+
+	e := &fakeExtractor{}
+	Register("", e, CapabilityImages)
+	Register("b", e, CapabilityPlaylist, CapabilityLogin)
+	Register("b", e, CapabilityLogin)
+	RegisterRoutes("b", e, Route{Host: "b.io"})
+	Register("a", e)
+	RegisterShortID("a", ShortID{Pattern: regexp.MustCompile(`^a\d+`)})
+
+	sites := Sites()
+	var names []string
+	for _, s := range sites {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"", "a", "b"}) {
+		t.Fatalf("Sites() = %q, want the universal extractor, a and b", names)
+	}
+	if len(sites[1].ShortIDs) != 1 || len(sites[1].Capabilities) != 0 {
+		t.Errorf("Sites()[a] = %+v", sites[1])
+	}
+	if len(sites[2].Routes) != 2 || !reflect.DeepEqual(sites[2].Capabilities, []Capability{CapabilityPlaylist, CapabilityLogin}) {
+		t.Errorf("Sites()[b] = %+v", sites[2])
+	}
+
+	Register("b", nil)
+	if sites = Sites(); len(sites) != 2 {
+		t.Errorf("Sites() = %+v, b should be removed", sites)
+	}
+}
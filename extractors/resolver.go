@@ -0,0 +1,72 @@
+package extractors
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/request"
+)
+
+// maxRedirects limits the redirects followed by Resolve.
+const maxRedirects = 10
+
+// shorteners are the hosts of the short links and tracking redirects,
+// the expand function rewrites a link without a request, nil means following the HTTP redirect.
+var shorteners = map[string]func(u *url.URL) string{
+	"t.co":   nil,
+	"bit.ly": nil,
+}
+
+// RegisterShortener registers a host of short links or tracking redirects, the links are resolved before
+// the extractor is chosen. expand rewrites a link without a request, the HTTP redirect is followed if it is nil.
+func RegisterShortener(host string, expand func(u *url.URL) string) {
+	lock.Lock()
+	shorteners[host] = expand
+	lock.Unlock()
+}
+
+// Resolve follows the short links and tracking redirects of the URL, it returns the final URL and the URLs
+// visited before it. The inputs that are not URLs, like short IDs, are returned as they are.
+func Resolve(client *request.Client, u string) (string, []string, error) {
+	u = strings.TrimSpace(u)
+	var chain []string
+	for {
+		parsed, err := url.ParseRequestURI(u)
+		if err != nil {
+			return u, chain, nil
+		}
+		lock.RLock()
+		expand, ok := shorteners[strings.ToLower(parsed.Hostname())]
+		lock.RUnlock()
+		if !ok {
+			return u, chain, nil
+		}
+		if len(chain) == maxRedirects {
+			return "", nil, errors.Errorf("too many redirects: %s", strings.Join(append(chain, u), " -> "))
+		}
+
+		var target string
+		if expand != nil {
+			target = expand(parsed)
+		} else if target, err = client.Location(u, nil); err != nil {
+			return "", nil, err
+		}
+		if target == "" || target == u {
+			return u, chain, nil
+		}
+		chain = append(chain, u)
+		u = target
+	}
+}
+
+// printChain prints the redirects of the URL in debug mode.
+func printChain(chain []string, u string) {
+	blue := color.New(color.FgBlue)
+	fmt.Println()
+	blue.Printf("Redirects:   ") // nolint
+	fmt.Println(strings.Join(append(chain, u), " -> "))
+}
@@ -0,0 +1,70 @@
+package extractors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	defer func(s map[string]func(*url.URL) string) {
+		shorteners = s
+	}(shorteners)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/short":
+			http.Redirect(w, r, "/tracking", http.StatusMovedPermanently)
+		case r.URL.Path == "/tracking":
+			http.Redirect(w, r, "https://www.example.com/v/1", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/loop/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/loop/"))
+			http.Redirect(w, r, server.URL+"/loop/"+strconv.Itoa(n+1), http.StatusFound)
+		default:
+			w.Write([]byte("ok")) // nolint
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	host = host[:strings.LastIndex(host, ":")]
+	shorteners = map[string]func(*url.URL) string{
+		host: nil,
+		"s.example.com": func(u *url.URL) string {
+			return "https://www.example.com/v" + u.Path
+		},
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		wantChain int
+		wantErr   bool
+	}{
+		{name: "redirect chain", input: server.URL + "/short", want: "https://www.example.com/v/1", wantChain: 2},
+		{name: "expand", input: "https://s.example.com/2", want: "https://www.example.com/v/2", wantChain: 1},
+		{name: "not a shortener", input: "https://www.example.com/v/3", want: "https://www.example.com/v/3"},
+		{name: "no redirect", input: server.URL + "/page", want: server.URL + "/page"},
+		{name: "short ID", input: "BV1xx411c7mD", want: "BV1xx411c7mD"},
+		{name: "too many redirects", input: server.URL + "/loop/0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, chain, err := Resolve(nil, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want || len(chain) != tt.wantChain {
+				t.Errorf("Resolve() = %s, %v, want %s with %d redirects", got, chain, tt.want, tt.wantChain)
+			}
+		})
+	}
+}
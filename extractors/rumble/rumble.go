@@ -20,7 +20,7 @@ import (
 )
 
 func init() {
-	extractors.Register("rumble", New())
+	extractors.Register("rumble", New(), extractors.CapabilityLive)
 }
 
 type extractor struct{}
@@ -42,7 +42,7 @@ type rumbleData struct {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	res, err := request.Request(http.MethodGet, url, nil, nil)
+	res, err := option.Client.Request(http.MethodGet, url, nil, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -84,7 +84,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(err)
 	}
 
-	streams, err := fetchVideoQuality(videoID)
+	streams, err := fetchVideoQuality(option.Client, videoID)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -217,8 +217,8 @@ func (rs *rumbleStreams) makeAllVODStreams(m map[string]*extractors.Stream) {
 var reResolution = regexp.MustCompile(`_(\d{3,4})p\/`) // ex. _720p/
 
 // Use this to create all the streams for live videos
-func (rs *rumbleStreams) makeAllLiveStreams(m map[string]*extractors.Stream) error {
-	playlists, err := utils.M3u8URLs(rs.FHLS.QAuto.URL)
+func (rs *rumbleStreams) makeAllLiveStreams(client *request.Client, m map[string]*extractors.Stream) error {
+	playlists, err := utils.M3u8URLs(client, rs.FHLS.QAuto.URL)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -246,7 +246,7 @@ func (rs *rumbleStreams) makeAllLiveStreams(m map[string]*extractors.Stream) err
 		}
 	}
 
-	tsURLs, err := utils.M3u8URLs(playlistURL)
+	tsURLs, err := utils.M3u8URLs(client, playlistURL)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -270,9 +270,9 @@ func (rs *rumbleStreams) makeAllLiveStreams(m map[string]*extractors.Stream) err
 	return nil
 }
 
-func (rs *rumbleStreams) makeAllNewVodStreams(m map[string]*extractors.Stream) error {
+func (rs *rumbleStreams) makeAllNewVodStreams(client *request.Client, m map[string]*extractors.Stream) error {
 	for size, details := range rs.FTAR {
-		playlists, err := utils.M3u8URLs(details.URL)
+		playlists, err := utils.M3u8URLs(client, details.URL)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -301,10 +301,10 @@ func (rs *rumbleStreams) makeAllNewVodStreams(m map[string]*extractors.Stream) e
 }
 
 // Request video formats and qualities
-func fetchVideoQuality(videoID string) (map[string]*extractors.Stream, error) {
+func fetchVideoQuality(client *request.Client, videoID string) (map[string]*extractors.Stream, error) {
 	reqURL := fmt.Sprintf(`https://rumble.com/embedJS/u3/?request=video&ver=2&v=%s&ext={"ad_count":null}&ad_wt=0`, videoID)
 
-	res, err := request.Request(http.MethodGet, reqURL, nil, nil)
+	res, err := client.Request(http.MethodGet, reqURL, nil, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -333,8 +333,8 @@ func fetchVideoQuality(videoID string) (map[string]*extractors.Stream, error) {
 
 	streams := make(map[string]*extractors.Stream, 9)
 	rs.makeAllVODStreams(streams)
-	_ = rs.makeAllLiveStreams(streams)
-	_ = rs.makeAllNewVodStreams(streams)
+	_ = rs.makeAllLiveStreams(client, streams)
+	_ = rs.makeAllNewVodStreams(client, streams)
 	return streams, nil
 }
 
@@ -7,14 +7,11 @@ import (
 	"github.com/robertkrimen/otto"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	e := New()
-	extractors.Register("streamtape", e)
-	extractors.Register("streamta", e) // streamta.pe
+	extractors.RegisterRoutes("streamtape", New(), extractors.Route{Domain: "streamtape"}, extractors.Route{Host: "streamta.pe"})
 }
 
 type extractor struct{}
@@ -25,8 +22,8 @@ func New() extractors.Extractor {
 }
 
 // Extract is the main function to extract the data.
-func (e *extractor) Extract(url string, _ extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -59,7 +56,7 @@ func (e *extractor) Extract(url string, _ extractors.Options) ([]*extractors.Dat
 		title = titleMatch[1]
 	}
 
-	size, err := request.Size(u, url)
+	size, err := option.Client.Size(u, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
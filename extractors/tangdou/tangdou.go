@@ -31,12 +31,12 @@ var defaultHeader = map[string]string{
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	return []*extractors.Data{tangdouDownload(url)}, nil
+	return []*extractors.Data{tangdouDownload(option.Client, url)}, nil
 }
 
 // tangdouDownload download function for single url
-func tangdouDownload(uri string) *extractors.Data {
-	html, err := request.Get(uri, uri, defaultHeader)
+func tangdouDownload(client *request.Client, uri string) *extractors.Data {
+	html, err := client.Get(uri, uri, defaultHeader)
 	if err != nil {
 		return extractors.EmptyData(uri, err)
 	}
@@ -59,7 +59,7 @@ func tangdouDownload(uri string) *extractors.Data {
 
 	realURL := strings.ReplaceAll(videoURLs[1], `\u002F`, "/")
 
-	size, err := request.Size(realURL, uri)
+	size, err := client.Size(realURL, uri)
 	if err != nil {
 		return extractors.EmptyData(uri, err)
 	}
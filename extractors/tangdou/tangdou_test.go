@@ -31,12 +31,13 @@ func TestTangDou(t *testing.T) {
 			if tt.playlist {
 				// playlist mode
 				_, err = New().Extract(tt.args.URL, extractors.Options{
+					Client:       test.Client(t),
 					Playlist:     true,
 					ThreadNumber: 9,
 				})
 				test.CheckError(t, err)
 			} else {
-				data, err = New().Extract(tt.args.URL, extractors.Options{})
+				data, err = New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 				test.CheckError(t, err)
 				test.Check(t, tt.args, data[0])
 			}
@@ -2,41 +2,25 @@ package threads
 
 import (
 	"fmt"
-	"net"
-	"net/http"
 	netURL "net/url"
 	"strings"
-	"time"
 
 	"github.com/gocolly/colly/v2"
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("threads", New())
+	extractors.Register("threads", New(), extractors.CapabilityImages)
 }
 
-type extractor struct {
-	client *http.Client
-}
+type extractor struct{}
 
-// New returns a instagram extractor.
+// New returns a threads extractor.
 func New() extractors.Extractor {
-	return &extractor{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-			Transport: &http.Transport{
-				Dial: (&net.Dialer{
-					Timeout: 5 * time.Second,
-				}).Dial,
-				TLSHandshakeTimeout: 5 * time.Second,
-			},
-		},
-	}
+	return &extractor{}
 }
 
 type media struct {
@@ -64,7 +48,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	title := fmt.Sprintf("Threads %s - %s", poster, shortCode)
 
 	collector := colly.NewCollector()
-	collector.SetClient(e.client)
+	collector.SetClient(option.Client.HTTPClient())
 
 	// case single image or video
 	collector.OnHTML("div.SingleInnerMediaContainer", func(e *colly.HTMLElement) {
@@ -111,11 +95,11 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	var parts []*extractors.Part
 
 	for _, m := range medias {
-		_, ext, err := utils.GetNameAndExt(m.URL)
+		_, ext, err := utils.GetNameAndExt(option.Client, m.URL)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
-		fileSize, err := request.Size(m.URL, url)
+		fileSize, err := option.Client.Size(m.URL, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
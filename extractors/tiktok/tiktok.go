@@ -7,11 +7,16 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
+	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("tiktok", New())
+	extractors.Register("tiktok", New(), extractors.CapabilityPlaylist)
+}
+
+// tiktok require a user agent
+var headers = map[string]string{
+	"User-Agent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0",
 }
 
 type extractor struct{}
@@ -23,10 +28,12 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, map[string]string{
-		// tiktok require a user agent
-		"User-Agent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0",
-	})
+	if user := userPattern.FindStringSubmatch(url); user != nil {
+		// a user is always the list of the videos of the user
+		return extractUser(user[1], option)
+	}
+
+	html, err := option.Client.Get(url, url, headers)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -75,7 +82,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 	streams := make(map[string]*extractors.Stream)
 
-	size, err := request.Size(videoURL, url)
+	size, err := option.Client.Size(videoURL, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -89,8 +96,14 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		Size:  size,
 	}
 
+	var id string
+	if ids := utils.MatchOneOf(url, `/video/(\d+)`); ids != nil {
+		id = ids[1]
+	}
+
 	return []*extractors.Data{
 		{
+			ID:      id,
 			Site:    "TikTok tiktok.com",
 			Title:   title,
 			Type:    extractors.DataTypeVideo,
@@ -31,9 +31,31 @@ func TestDownload(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			data, err := New().Extract(tt.args.URL, extractors.Options{})
+			data, err := New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 			test.CheckError(t, err)
 			test.Check(t, tt.args, data[0])
 		})
 	}
 }
+
+func TestUserPattern(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://www.tiktok.com/@enhypen", want: "enhypen"},
+		{url: "https://www.tiktok.com/@ginjiro_koyama/?lang=en", want: "ginjiro_koyama"},
+		{url: "https://www.tiktok.com/@enhypen/video/7165445991238356225"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			var got string
+			if m := userPattern.FindStringSubmatch(tt.url); m != nil {
+				got = m[1]
+			}
+			if got != tt.want {
+				t.Errorf("user of %s = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package tiktok
+
+import (
+	"encoding/json"
+	"fmt"
+	netURL "net/url"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/utils"
+)
+
+const itemListAPI = "https://www.tiktok.com/api/post/item_list/?aid=1988&count=%d&cursor=%s&secUid=%s"
+
+const userPageSize = 35
+
+// userPattern matches the user pages, eg: https://www.tiktok.com/@tiktok, but not their videos.
+var userPattern = regexp.MustCompile(`tiktok\.com/@([\w.-]+)/?(?:[?#]|$)`)
+
+// {"itemList":[{"id":"7000000000000000000","desc":"a"}],"hasMore":true,"cursor":"1700000000000"}
+type itemList struct {
+	StatusCode int `json:"statusCode"`
+	ItemList   []struct {
+		ID   string `json:"id"`
+		Desc string `json:"desc"`
+	} `json:"itemList"`
+	HasMore bool   `json:"hasMore"`
+	Cursor  string `json:"cursor"`
+}
+
+// extractUser extracts the videos of the user, they are numbered from the newest one.
+func extractUser(user string, option extractors.Options) ([]*extractors.Data, error) {
+	userURL := "https://www.tiktok.com/@" + user
+	html, err := option.Client.Get(userURL, userURL, headers)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	secUID := utils.MatchOneOf(html, `"secUid":"([^"]+)"`)
+	if secUID == nil {
+		return nil, errors.WithStack(extractors.ErrURLParseFailed)
+	}
+
+	urls, err := extractors.Paginate(option, func(cursor string) (*extractors.Page, error) {
+		if cursor == "" {
+			cursor = "0"
+		}
+		api := fmt.Sprintf(itemListAPI, userPageSize, cursor, netURL.QueryEscape(secUID[1]))
+		jsonData, err := option.Client.Get(api, userURL, headers)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var list itemList
+		if err = json.Unmarshal([]byte(jsonData), &list); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if list.StatusCode != 0 {
+			return nil, errors.Errorf("tiktok user %s error: status code %d", user, list.StatusCode)
+		}
+		page := &extractors.Page{}
+		for _, item := range list.ItemList {
+			page.URLs = append(page.URLs, userURL+"/video/"+item.ID)
+		}
+		if list.HasMore {
+			page.Next = list.Cursor
+		}
+		return page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &extractor{}
+	return extractors.ExtractItems(urls, option, func(url string) *extractors.Data {
+		data, err := e.Extract(url, option)
+		if err != nil {
+			return extractors.EmptyData(url, err)
+		}
+		return data[0]
+	}), nil
+}
@@ -13,7 +13,7 @@ import (
 )
 
 func init() {
-	extractors.Register("tumblr", New())
+	extractors.Register("tumblr", New(), extractors.CapabilityImages)
 }
 
 type imageList struct {
@@ -28,12 +28,12 @@ type tumblrImage struct {
 	Image string `json:"image"`
 }
 
-func genURLData(url, referer string) (*extractors.Part, int64, error) {
-	size, err := request.Size(url, referer)
+func genURLData(client *request.Client, url, referer string) (*extractors.Part, int64, error) {
+	size, err := client.Size(url, referer)
 	if err != nil {
 		return nil, 0, err
 	}
-	_, ext, err := utils.GetNameAndExt(url)
+	_, ext, err := utils.GetNameAndExt(client, url)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -44,7 +44,7 @@ func genURLData(url, referer string) (*extractors.Part, int64, error) {
 	}, size, nil
 }
 
-func tumblrImageDownload(url, html, title string) ([]*extractors.Data, error) {
+func tumblrImageDownload(client *request.Client, url, html, title string) ([]*extractors.Data, error) {
 	jsonStrings := utils.MatchOneOf(
 		html, `<script type="application/ld\+json">\s*(.+?)</script>`,
 	)
@@ -62,7 +62,7 @@ func tumblrImageDownload(url, html, title string) ([]*extractors.Data, error) {
 			return nil, errors.WithStack(err)
 		}
 		for _, u := range imageList.Image.List {
-			urlData, size, err := genURLData(u, url)
+			urlData, size, err := genURLData(client, u, url)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -75,7 +75,7 @@ func tumblrImageDownload(url, html, title string) ([]*extractors.Data, error) {
 			return nil, errors.WithStack(err)
 		}
 
-		urlData, size, err := genURLData(image.Image, url)
+		urlData, size, err := genURLData(client, image.Image, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -100,7 +100,7 @@ func tumblrImageDownload(url, html, title string) ([]*extractors.Data, error) {
 	}, nil
 }
 
-func tumblrVideoDownload(url, html, title string) ([]*extractors.Data, error) {
+func tumblrVideoDownload(client *request.Client, url, html, title string) ([]*extractors.Data, error) {
 	videoURLs := utils.MatchOneOf(html, `<iframe src='(.+?)'`)
 	if videoURLs == nil || len(videoURLs) < 2 {
 		return nil, errors.WithStack(extractors.ErrURLParseFailed)
@@ -110,7 +110,7 @@ func tumblrVideoDownload(url, html, title string) ([]*extractors.Data, error) {
 	if !strings.Contains(videoURL, "tumblr.com/video") {
 		return nil, errors.New("lux doesn't support this URL right now")
 	}
-	videoHTML, err := request.Get(videoURL, url, nil)
+	videoHTML, err := client.Get(videoURL, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -121,7 +121,7 @@ func tumblrVideoDownload(url, html, title string) ([]*extractors.Data, error) {
 	}
 	realURL := realURLs[1]
 
-	urlData, size, err := genURLData(realURL, url)
+	urlData, size, err := genURLData(client, realURL, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -152,7 +152,7 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -164,8 +164,8 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	title := parser.Title(doc)
 	if strings.Contains(html, "<iframe src=") {
 		// Data
-		return tumblrVideoDownload(url, html, title)
+		return tumblrVideoDownload(option.Client, url, html, title)
 	}
 	// Image
-	return tumblrImageDownload(url, html, title)
+	return tumblrImageDownload(option.Client, url, html, title)
 }
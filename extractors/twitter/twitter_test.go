@@ -40,7 +40,7 @@ func TestDownload(t *testing.T) {
 	// The file size changes every time (caused by CDN?), so the size is not checked here
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			New().Extract(tt.args.URL, extractors.Options{})
+			New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 		})
 	}
 }
@@ -1,10 +1,24 @@
 package extractors
 
+import "github.com/iawia002/lux/request"
+
 // Part is the data structure for a single part of the video stream information.
 type Part struct {
 	URL  string `json:"url"`
 	Size int64  `json:"size"`
 	Ext  string `json:"ext"`
+	// Duration is the duration of the segment in seconds, it is set for the segments of HLS streams,
+	// only the segments covering the section are downloaded with --download-sections.
+	Duration float64 `json:"duration,omitempty"`
+	// Segments are the segments of a DASH track, they are downloaded in order into the file of the part,
+	// URL is the manifest then. The initialization segment has no duration.
+	Segments []Segment `json:"segments,omitempty"`
+}
+
+// Segment is a segment of a DASH track, Duration is in seconds.
+type Segment struct {
+	URL      string  `json:"url"`
+	Duration float64 `json:"duration,omitempty"`
 }
 
 type CaptionPart struct {
@@ -30,6 +44,13 @@ type Stream struct {
 	NeedMux bool
 }
 
+// Chapter is a chapter of a video, Start and End are the offsets in seconds.
+type Chapter struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Title string  `json:"title"`
+}
+
 // DataType indicates the type of extracted data, eg: video or image.
 type DataType string
 
@@ -40,19 +61,27 @@ const (
 	DataTypeImage DataType = "image"
 	// DataTypeAudio indicates the type of extracted data is the audio.
 	DataTypeAudio DataType = "audio"
+	// DataTypeFile indicates the extracted data is another kind of file, eg: a PDF document.
+	DataTypeFile DataType = "file"
 )
 
 // Data is the main data structure for the whole video data.
 type Data struct {
 	// URL is used to record the address of this download
-	URL   string   `json:"url"`
-	Site  string   `json:"site"`
-	Title string   `json:"title"`
-	Type  DataType `json:"type"`
+	URL string `json:"url"`
+	// ID identifies the item in its site, eg: the video ID, it tells the items of a playlist apart
+	ID string `json:"id,omitempty"`
+	// OriginalURL is the short link or redirect that URL was resolved from
+	OriginalURL string   `json:"original_url,omitempty"`
+	Site        string   `json:"site"`
+	Title       string   `json:"title"`
+	Type        DataType `json:"type"`
 	// each stream has it's own Parts and Quality
 	Streams map[string]*Stream `json:"streams"`
 	// danmaku, subtitles, etc
 	Captions map[string]*CaptionPart `json:"caption"`
+	// Chapters are in order, the End of the last chapter is the duration of the video
+	Chapters []Chapter `json:"chapters,omitempty"`
 	// Err is used to record whether an error occurred when extracting the list data
 	Err error `json:"err"`
 }
@@ -98,27 +127,43 @@ func EmptyData(url string, err error) *Data {
 	}
 }
 
-// Options defines optional options that can be used in the extraction function.
+// Options defines optional options that can be used in the extraction function,
+// they are sent to the plugins in JSON.
 type Options struct {
 	// Playlist indicates if we need to extract the whole playlist rather than the single video.
-	Playlist bool
+	Playlist bool `json:"playlist"`
 	// Items defines wanted items from a playlist. Separated by commas like: 1,5,6,8-10.
-	Items string
+	Items string `json:"items"`
 	// ItemStart defines the starting item of a playlist.
-	ItemStart int
+	ItemStart int `json:"item_start"`
 	// ItemEnd defines the ending item of a playlist.
-	ItemEnd int
+	ItemEnd int `json:"item_end"`
+	// OldestFirst orders the items of channels and user profiles from the oldest one, they are newest first by default.
+	OldestFirst bool `json:"oldest_first"`
+	// SkipItem reports whether the item of a playlist with the URL is left out before it is extracted,
+	// eg: the items downloaded before. The URL of the data of an item is its URL in the playlist.
+	SkipItem func(url string) bool `json:"-"`
 
 	// ThreadNumber defines how many threads will use in the extraction, only works when Playlist is true.
-	ThreadNumber int
-	Cookie       string
+	ThreadNumber int    `json:"thread_number"`
+	Cookie       string `json:"cookie"`
+	// Client sends the requests of the extraction, nil means the default client.
+	Client *request.Client `json:"-"`
+
+	// Username and Password are used to log in to the sites that support it.
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Netrc reads the username and password from the .netrc file if Username is empty,
+	// the machine is NetrcMachine, or the name of the site, eg: vimeo.
+	Netrc        bool   `json:"netrc"`
+	NetrcMachine string `json:"netrc_machine"`
 
 	// EpisodeTitleOnly indicates file name of each bilibili episode doesn't include the playlist title
-	EpisodeTitleOnly bool
+	EpisodeTitleOnly bool `json:"episode_title_only"`
 
-	YoukuCcode    string
-	YoukuCkey     string
-	YoukuPassword string
+	YoukuCcode    string `json:"youku_ccode"`
+	YoukuCkey     string `json:"youku_ckey"`
+	YoukuPassword string `json:"youku_password"`
 }
 
 // Extractor implements video data extraction related operations.
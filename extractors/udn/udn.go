@@ -6,7 +6,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -54,7 +53,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(extractors.ErrURLParseFailed)
 	}
 
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -70,11 +69,11 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	if cdnURL == "" {
 		return nil, errors.New("empty list")
 	}
-	srcURL, err := request.Get("http://"+cdnURL, url, nil)
+	srcURL, err := option.Client.Get("http://"+cdnURL, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	size, err := request.Size(srcURL, url)
+	size, err := option.Client.Size(srcURL, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
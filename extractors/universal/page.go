@@ -0,0 +1,377 @@
+package universal
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
+	"github.com/iawia002/lux/utils"
+)
+
+// embeddedMediaPattern matches the media links in the scripts and attributes of a page.
+var embeddedMediaPattern = regexp.MustCompile(`https?://[^\s"'<>\\]+?\.(?:m3u8|mpd|mp4)\b(?:\?[^\s"'<>\\]*)?`)
+
+// candidate is a media URL found in a page.
+type candidate struct {
+	url      string
+	dataType extractors.DataType
+	// quality is the label of the media in the page, eg: 720p
+	quality string
+	// ext is the extension of the media if the URL has none, eg: the extension of the probed content type
+	ext string
+}
+
+// page is the media information found in an HTML page.
+type page struct {
+	title      string
+	candidates []candidate
+}
+
+// discover looks for the media in the HTML page, the candidates are ordered by the reliability of their source:
+// JSON-LD, Open Graph and Twitter cards, <video> and <audio> tags, and then the media links in the page.
+// The images are only used if there are no videos or audios.
+func discover(pageURL *url.URL, content string) (*page, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	p := &page{}
+	seen := make(map[string]bool)
+	add := func(raw string, dataType extractors.DataType, quality string) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return
+		}
+		u, err := pageURL.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			// blob: and data: URLs can't be downloaded
+			return
+		}
+		s := u.String()
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		p.candidates = append(p.candidates, candidate{url: s, dataType: dataType, quality: quality})
+	}
+
+	var ldTitle string
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var v interface{}
+		if json.Unmarshal([]byte(s.Text()), &v) != nil {
+			return
+		}
+		for _, object := range mediaObjects(v) {
+			dataType := extractors.DataTypeVideo
+			if object["@type"] == "AudioObject" {
+				dataType = extractors.DataTypeAudio
+			}
+			if contentURL, ok := object["contentUrl"].(string); ok {
+				add(contentURL, dataType, "")
+			}
+			if embedURL, ok := object["embedUrl"].(string); ok && isMediaURL(embedURL) {
+				add(embedURL, dataType, "")
+			}
+			if name, ok := object["name"].(string); ok && ldTitle == "" {
+				ldTitle = name
+			}
+		}
+	})
+
+	for _, name := range []string{"og:video:secure_url", "og:video:url", "og:video"} {
+		for _, content := range metaContents(doc, name) {
+			add(content, extractors.DataTypeVideo, "")
+		}
+	}
+	for _, content := range metaContents(doc, "twitter:player:stream") {
+		add(content, extractors.DataTypeVideo, "")
+	}
+	for _, content := range metaContents(doc, "twitter:player") {
+		// it is usually an embedded player page
+		if isMediaURL(content) {
+			add(content, extractors.DataTypeVideo, "")
+		}
+	}
+	mediaTags(doc, "video", extractors.DataTypeVideo, add)
+
+	for _, name := range []string{"og:audio:secure_url", "og:audio:url", "og:audio"} {
+		for _, content := range metaContents(doc, name) {
+			add(content, extractors.DataTypeAudio, "")
+		}
+	}
+	mediaTags(doc, "audio", extractors.DataTypeAudio, add)
+
+	// the links in scripts are often JSON escaped
+	for _, link := range embeddedMediaPattern.FindAllString(strings.ReplaceAll(content, `\/`, "/"), -1) {
+		add(html.UnescapeString(link), extractors.DataTypeVideo, "")
+	}
+
+	if len(p.candidates) == 0 {
+		for _, name := range []string{"og:image:secure_url", "og:image:url", "og:image"} {
+			for _, content := range metaContents(doc, name) {
+				add(content, extractors.DataTypeImage, "")
+			}
+		}
+	}
+
+	p.title = firstNonEmpty(append(metaContents(doc, "og:title"), ldTitle, strings.TrimSpace(doc.Find("title").First().Text()))...)
+	return p, nil
+}
+
+// metaContents returns the contents of the <meta> tags with the property or name.
+func metaContents(doc *goquery.Document, name string) []string {
+	var contents []string
+	doc.Find("meta").Each(func(_ int, s *goquery.Selection) {
+		if s.AttrOr("property", "") != name && s.AttrOr("name", "") != name {
+			return
+		}
+		if content := strings.TrimSpace(s.AttrOr("content", "")); content != "" {
+			contents = append(contents, content)
+		}
+	})
+	return contents
+}
+
+// mediaTags adds the sources of the <video> or <audio> tags, the quality is the label of the <source> tag.
+func mediaTags(doc *goquery.Document, tag string, dataType extractors.DataType, add func(string, extractors.DataType, string)) {
+	doc.Find(tag).Each(func(_ int, s *goquery.Selection) {
+		add(s.AttrOr("src", ""), dataType, "")
+		s.Find("source").Each(func(_ int, source *goquery.Selection) {
+			quality := firstNonEmpty(source.AttrOr("label", ""), source.AttrOr("res", ""), source.AttrOr("size", ""), source.AttrOr("data-quality", ""))
+			add(source.AttrOr("src", ""), dataType, quality)
+		})
+	})
+}
+
+// mediaObjects returns the VideoObject and AudioObject in the JSON-LD data, the objects can be nested or in a @graph.
+func mediaObjects(v interface{}) []map[string]interface{} {
+	var objects []map[string]interface{}
+	switch value := v.(type) {
+	case []interface{}:
+		for _, item := range value {
+			objects = append(objects, mediaObjects(item)...)
+		}
+	case map[string]interface{}:
+		switch value["@type"] {
+		case "VideoObject", "AudioObject":
+			objects = append(objects, value)
+		}
+		for key, item := range value {
+			if key != "@type" {
+				objects = append(objects, mediaObjects(item)...)
+			}
+		}
+	}
+	return objects
+}
+
+// isMediaURL reports whether the URL is a media file or a streaming manifest by its extension.
+func isMediaURL(u string) bool {
+	switch mediaExt(u) {
+	case "mp4", "m4v", "webm", "mov", "flv", "m3u8", "mpd", "mp3", "m4a", "aac", "ogg", "opus", "wav":
+		return true
+	}
+	return false
+}
+
+// mediaExt returns the lowercase extension of the URL path without the dot.
+func mediaExt(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(path.Ext(parsed.Path), "."))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// dashStream returns the stream of the best video and audio tracks of a DASH manifest, the segments of a track
+// are downloaded into the file of its part.
+func dashStream(client *request.Client, c candidate) (*extractors.Stream, error) {
+	reps, err := utils.DashRepresentations(client, c.url)
+	if err != nil {
+		return nil, err
+	}
+	var video, audio *utils.DashRepresentation
+	for i := range reps {
+		r := &reps[i]
+		switch {
+		case strings.HasPrefix(r.MimeType, "video/"):
+			if video == nil || r.Bandwidth > video.Bandwidth {
+				video = r
+			}
+		case strings.HasPrefix(r.MimeType, "audio/"):
+			if audio == nil || r.Bandwidth > audio.Bandwidth {
+				audio = r
+			}
+		}
+	}
+
+	stream := &extractors.Stream{Quality: c.quality}
+	for _, r := range []*utils.DashRepresentation{video, audio} {
+		if r == nil {
+			continue
+		}
+		ext := strings.TrimPrefix(r.MimeType, "video/")
+		if r == audio {
+			ext = "m4a"
+			if r.MimeType == "audio/webm" {
+				ext = "webm"
+			}
+		}
+		part := &extractors.Part{URL: c.url, Ext: ext}
+		for _, s := range r.Segments {
+			part.Segments = append(part.Segments, extractors.Segment{URL: s.URL, Duration: s.Duration})
+		}
+		stream.Parts = append(stream.Parts, part)
+	}
+	if len(stream.Parts) == 0 {
+		return nil, errors.Errorf("no video or audio tracks in %s", c.url)
+	}
+	stream.NeedMux = len(stream.Parts) > 1
+	if stream.Quality == "" && video != nil && video.Height > 0 {
+		stream.Quality = strconv.Itoa(video.Height) + "p"
+	}
+	return stream, nil
+}
+
+// candidateStream returns the stream of the candidate, the segments of an HLS playlist are the parts of the stream,
+// the tracks of a DASH manifest are the parts of the stream.
+func candidateStream(client *request.Client, c candidate, refer string) (*extractors.Stream, error) {
+	ext := firstNonEmpty(mediaExt(c.url), c.ext)
+	if ext == "mpd" {
+		return dashStream(client, c)
+	}
+	if ext == "m3u8" {
+		segments, err := utils.M3u8Segments(client, c.url)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(segments) == 0 {
+			return nil, errors.Errorf("no segments in %s", c.url)
+		}
+		parts := make([]*extractors.Part, 0, len(segments))
+		for _, s := range segments {
+			parts = append(parts, &extractors.Part{URL: s.URL, Ext: "ts", Duration: s.Duration})
+		}
+		return &extractors.Stream{Quality: c.quality, Parts: parts}, nil
+	}
+
+	// the size is unknown if the probing fails, the media can still be downloaded
+	var size int64
+	if f, err := probe(client, c.url, refer); err == nil {
+		size = f.size
+		if ext == "" {
+			ext = f.ext
+		}
+	}
+	if ext == "" {
+		switch c.dataType {
+		case extractors.DataTypeAudio:
+			ext = "mp3"
+		case extractors.DataTypeImage:
+			ext = "jpg"
+		default:
+			ext = "mp4"
+		}
+	}
+	return &extractors.Stream{
+		Quality: c.quality,
+		Parts:   []*extractors.Part{{URL: c.url, Size: size, Ext: ext}},
+		Size:    size,
+	}, nil
+}
+
+// extractPage returns the media found in the HTML page, it returns nil if there is none.
+// Several media are the streams of one Data, or a playlist of Data if option.Playlist is true.
+func extractPage(pageURL, content string, option extractors.Options) ([]*extractors.Data, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	p, err := discover(u, content)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.candidates) == 0 {
+		return nil, nil
+	}
+	title := p.title
+	if title == "" {
+		title = path.Base(u.Path)
+	}
+
+	if option.Playlist && len(p.candidates) > 1 {
+		items := utils.NeedDownloadList(option.Items, option.ItemStart, option.ItemEnd, len(p.candidates))
+		data := make([]*extractors.Data, 0, len(items))
+		for _, item := range items {
+			c := p.candidates[item-1]
+			if option.SkipItem != nil && option.SkipItem(c.url) {
+				continue
+			}
+			stream, err := candidateStream(option.Client, c, pageURL)
+			if err != nil {
+				data = append(data, extractors.EmptyData(c.url, err))
+				continue
+			}
+			data = append(data, &extractors.Data{
+				Site:    "Universal",
+				Title:   title + " " + strconv.Itoa(item),
+				Type:    c.dataType,
+				Streams: map[string]*extractors.Stream{"default": stream},
+				URL:     pageURL,
+				// the media URL tells the items of the page apart
+				ID: c.url,
+			})
+		}
+		return data, nil
+	}
+
+	streams := make(map[string]*extractors.Stream, len(p.candidates))
+	var firstErr error
+	for i, c := range p.candidates {
+		stream, err := candidateStream(option.Client, c, pageURL)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		id := "default"
+		if len(p.candidates) > 1 {
+			id = strconv.Itoa(i)
+			if c.quality != "" && streams[c.quality] == nil {
+				id = c.quality
+			}
+		}
+		streams[id] = stream
+	}
+	if len(streams) == 0 {
+		return nil, firstErr
+	}
+	return []*extractors.Data{
+		{
+			Site:    "Universal",
+			Title:   title,
+			Type:    p.candidates[0].dataType,
+			Streams: streams,
+			URL:     pageURL,
+		},
+	}, nil
+}
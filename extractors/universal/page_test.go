@@ -0,0 +1,187 @@
+package universal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+func TestDiscover(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		wantTitle string
+		want      []candidate
+	}{
+		{
+			name: "open graph",
+			html: `<html><head><title>Page</title>
+<meta property="og:title" content="OG title">
+<meta property="og:video" content="/v/1.mp4">
+<meta property="og:video:secure_url" content="https://example.com/v/1.mp4">
+<meta property="og:image" content="https://example.com/cover.jpg">
+</head></html>`,
+			wantTitle: "OG title",
+			want: []candidate{
+				{url: "https://example.com/v/1.mp4", dataType: extractors.DataTypeVideo},
+			},
+		},
+		{
+			name: "video tags",
+			html: `<html><head><title>Page</title></head><body>
+<video src="blob:https://example.com/1"><source src="/720.mp4" label="720p"><source src="/1080.mp4" res="1080"></video>
+<audio src="a.mp3"></audio>
+</body></html>`,
+			wantTitle: "Page",
+			want: []candidate{
+				{url: "https://example.com/720.mp4", dataType: extractors.DataTypeVideo, quality: "720p"},
+				{url: "https://example.com/1080.mp4", dataType: extractors.DataTypeVideo, quality: "1080"},
+				{url: "https://example.com/page/a.mp3", dataType: extractors.DataTypeAudio},
+			},
+		},
+		{
+			name: "json-ld and twitter card",
+			html: `<html><head>
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[{"@type":"WebPage"},{"@type":"VideoObject","name":"LD title","contentUrl":"https://cdn.example.com/v.mp4","embedUrl":"https://example.com/embed/1"}]}</script>
+<meta name="twitter:player" content="https://example.com/player/1">
+<meta name="twitter:player:stream" content="https://cdn.example.com/stream.mp4">
+</head></html>`,
+			wantTitle: "LD title",
+			want: []candidate{
+				{url: "https://cdn.example.com/v.mp4", dataType: extractors.DataTypeVideo},
+				{url: "https://cdn.example.com/stream.mp4", dataType: extractors.DataTypeVideo},
+			},
+		},
+		{
+			name: "embedded links",
+			html: `<html><body><script>var config = {"hls":"https:\/\/cdn.example.com\/live\/index.m3u8?token=1","dash":"https://cdn.example.com/v.mpd"};</script>
+<a href="https://cdn.example.com/v.mp4?a=1&amp;b=2">download</a></body></html>`,
+			want: []candidate{
+				{url: "https://cdn.example.com/live/index.m3u8?token=1", dataType: extractors.DataTypeVideo},
+				{url: "https://cdn.example.com/v.mpd", dataType: extractors.DataTypeVideo},
+				{url: "https://cdn.example.com/v.mp4?a=1&b=2", dataType: extractors.DataTypeVideo},
+			},
+		},
+		{
+			name:      "image only",
+			html:      `<html><head><meta property="og:image" content="https://example.com/cover.jpg"></head></html>`,
+			wantTitle: "",
+			want: []candidate{
+				{url: "https://example.com/cover.jpg", dataType: extractors.DataTypeImage},
+			},
+		},
+		{
+			name:      "nothing",
+			html:      `<html><head><title>Page</title></head><body>text</body></html>`,
+			wantTitle: "Page",
+		},
+	}
+	pageURL, _ := url.Parse("https://example.com/page/")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := discover(pageURL, tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got.title, tt.wantTitle)
+			}
+			if !reflect.DeepEqual(got.candidates, tt.want) {
+				t.Errorf("candidates = %+v, want %+v", got.candidates, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><title>Test page</title></head><body><video>` + // nolint
+				`<source src="/720.mp4" label="720p"><source src="/live.m3u8" label="hls"></video></body></html>`))
+		case "/empty":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body>nothing</body></html>`)) // nolint
+		case "/live.m3u8":
+			w.Write([]byte("#EXTM3U\n#EXTINF:10,\n0.ts\n#EXTINF:10,\n1.ts\n")) // nolint
+		case "/manifest":
+			w.Header().Set("Content-Type", "application/dash+xml")
+			w.Write([]byte(`<MPD type="static" mediaPresentationDuration="PT8S"><Period>` + // nolint
+				`<AdaptationSet mimeType="video/mp4"><SegmentTemplate initialization="v/init.mp4" media="v/$Number$.m4s" duration="4"/>` +
+				`<Representation id="360p" bandwidth="500000" height="360"/><Representation id="720p" bandwidth="2000000" height="720"/></AdaptationSet>` +
+				`<AdaptationSet mimeType="audio/mp4"><Representation id="audio" bandwidth="128000"><BaseURL>audio.m4a</BaseURL></Representation></AdaptationSet>` +
+				`</Period></MPD>`))
+		default:
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Write([]byte(strings.Repeat("0", 100))) // nolint
+		}
+	}))
+	defer server.Close()
+
+	t.Run("streams", func(t *testing.T) {
+		data, err := New().Extract(server.URL+"/page", extractors.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 1 || data[0].Title != "Test page" || data[0].Type != extractors.DataTypeVideo {
+			t.Fatalf("unexpected data: %+v", data)
+		}
+		if s := data[0].Streams["720p"]; s == nil || s.Size != 100 || s.Parts[0].Ext != "mp4" {
+			t.Errorf("unexpected 720p stream: %+v", s)
+		}
+		if s := data[0].Streams["hls"]; s == nil || len(s.Parts) != 2 || s.Parts[1].URL != server.URL+"/1.ts" {
+			t.Errorf("unexpected hls stream: %+v", s)
+		}
+	})
+
+	t.Run("playlist", func(t *testing.T) {
+		data, err := New().Extract(server.URL+"/page", extractors.Options{Playlist: true, ItemStart: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 2 || data[0].Title != "Test page 1" || data[1].Title != "Test page 2" {
+			t.Errorf("unexpected playlist: %+v", data)
+		}
+	})
+
+	t.Run("dash", func(t *testing.T) {
+		data, err := New().Extract(server.URL+"/manifest", extractors.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 1 || data[0].Type != extractors.DataTypeVideo {
+			t.Fatalf("unexpected data: %+v", data)
+		}
+		s := data[0].Streams["default"]
+		if s == nil || !s.NeedMux || s.Quality != "720p" || len(s.Parts) != 2 {
+			t.Fatalf("unexpected dash stream: %+v", s)
+		}
+		want := []extractors.Segment{
+			{URL: server.URL + "/v/init.mp4"},
+			{URL: server.URL + "/v/1.m4s", Duration: 4},
+			{URL: server.URL + "/v/2.m4s", Duration: 4},
+		}
+		if s.Parts[0].Ext != "mp4" || !reflect.DeepEqual(s.Parts[0].Segments, want) {
+			t.Errorf("unexpected video track: %+v", s.Parts[0])
+		}
+		if s.Parts[1].Ext != "m4a" || !reflect.DeepEqual(s.Parts[1].Segments, []extractors.Segment{{URL: server.URL + "/audio.m4a"}}) {
+			t.Errorf("unexpected audio track: %+v", s.Parts[1])
+		}
+	})
+
+	t.Run("no media", func(t *testing.T) {
+		data, err := New().Extract(server.URL+"/empty", extractors.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 1 || data[0].Title != "empty" {
+			t.Errorf("the page itself should be downloaded: %+v", data)
+		}
+	})
+}
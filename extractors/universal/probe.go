@@ -0,0 +1,197 @@
+package universal
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
+)
+
+// mediaTypes maps the MIME types to the file extensions, the first one is used if a type or an extension appears twice.
+var mediaTypes = []struct {
+	mimeType string
+	ext      string
+}{
+	{"video/mp4", "mp4"},
+	{"video/x-m4v", "m4v"},
+	{"video/webm", "webm"},
+	{"video/x-matroska", "mkv"},
+	{"video/quicktime", "mov"},
+	{"video/x-flv", "flv"},
+	{"video/mp2t", "ts"},
+	{"video/x-msvideo", "avi"},
+	{"video/3gpp", "3gp"},
+	{"audio/mpeg", "mp3"},
+	{"audio/mp4", "m4a"},
+	{"audio/x-m4a", "m4a"},
+	{"audio/aac", "aac"},
+	{"audio/ogg", "ogg"},
+	{"audio/opus", "opus"},
+	{"audio/flac", "flac"},
+	{"audio/wav", "wav"},
+	{"audio/x-wav", "wav"},
+	{"image/jpeg", "jpg"},
+	{"image/jpeg", "jpeg"},
+	{"image/png", "png"},
+	{"image/gif", "gif"},
+	{"image/webp", "webp"},
+	{"image/avif", "avif"},
+	{"image/svg+xml", "svg"},
+	{"image/bmp", "bmp"},
+	{"application/vnd.apple.mpegurl", "m3u8"},
+	{"application/x-mpegurl", "m3u8"},
+	{"application/dash+xml", "mpd"},
+	{"application/pdf", "pdf"},
+	{"application/zip", "zip"},
+	{"text/html", "html"},
+	{"text/plain", "txt"},
+}
+
+// extByType returns the file extension of the MIME type.
+func extByType(mimeType string) string {
+	for _, t := range mediaTypes {
+		if t.mimeType == mimeType {
+			return t.ext
+		}
+	}
+	if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+		return strings.TrimPrefix(exts[0], ".")
+	}
+	return ""
+}
+
+// knownExt reports whether the file extension is one of the known media types, eg: not "php" of a script.
+func knownExt(ext string) bool {
+	ext = strings.ToLower(ext)
+	for _, t := range mediaTypes {
+		if t.ext == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// typeByExt returns the MIME type of the file extension.
+func typeByExt(ext string) string {
+	ext = strings.ToLower(ext)
+	for _, t := range mediaTypes {
+		if t.ext == ext {
+			return t.mimeType
+		}
+	}
+	mimeType, _, _ := mime.ParseMediaType(mime.TypeByExtension("." + ext))
+	return mimeType
+}
+
+// mimeDataType maps the MIME type onto the DataType.
+func mimeDataType(mimeType string) extractors.DataType {
+	switch strings.SplitN(mimeType, "/", 2)[0] {
+	case "video":
+		return extractors.DataTypeVideo
+	case "audio":
+		return extractors.DataTypeAudio
+	case "image":
+		return extractors.DataTypeImage
+	}
+	switch mimeType {
+	case "application/vnd.apple.mpegurl", "application/x-mpegurl", "application/dash+xml":
+		return extractors.DataTypeVideo
+	}
+	return extractors.DataTypeFile
+}
+
+// file is the information of a direct link.
+type file struct {
+	// mimeType is the Content-Type without the parameters, or the type of the extension if the server doesn't tell
+	mimeType string
+	// size is 0 if it is unknown
+	size int64
+	name string
+	ext  string
+}
+
+// probe gets the information of the URL without downloading it, it asks for the first byte only,
+// and sends a HEAD request if the complete length is not in the response.
+func probe(client *request.Client, u, refer string) (*file, error) {
+	res, err := client.Request(http.MethodGet, u, nil, map[string]string{
+		"Referer": refer,
+		"Range":   "bytes=0-0",
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// the body is not needed, servers that ignore the Range header would send the whole file
+	res.Body.Close() // nolint
+
+	h := res.Header
+	var size int64
+	if res.StatusCode == http.StatusPartialContent {
+		size = request.ContentRangeTotal(h.Get("Content-Range"))
+		if size == 0 {
+			// bytes 0-0/*, the Content-Length of a HEAD request is the complete length
+			if head, err := client.Request(http.MethodHead, u, nil, map[string]string{"Referer": refer}); err == nil {
+				head.Body.Close() // nolint
+				size = contentLength(head.Header)
+			}
+		}
+	} else {
+		size = contentLength(h)
+	}
+
+	f := &file{size: size}
+	f.mimeType, _, _ = mime.ParseMediaType(h.Get("Content-Type"))
+	f.name, f.ext = fileName(h.Get("Content-Disposition"), u)
+	// the extension of the type wins over an extension that is not a media type, eg: download.php of a video
+	if !knownExt(f.ext) && f.mimeType != "application/octet-stream" {
+		if ext := extByType(f.mimeType); ext != "" {
+			f.ext = ext
+		}
+	}
+	if f.mimeType == "" || f.mimeType == "application/octet-stream" {
+		if mimeType := typeByExt(f.ext); mimeType != "" {
+			f.mimeType = mimeType
+		}
+	}
+	return f, nil
+}
+
+// fileName returns the name and extension of the file, from the Content-Disposition header or the URL path.
+func fileName(contentDisposition, u string) (string, string) {
+	var base string
+	// filename* in RFC 5987 is decoded as filename
+	if _, params, err := mime.ParseMediaType(contentDisposition); err == nil {
+		base = path.Base(strings.ReplaceAll(params["filename"], `\`, "/"))
+	}
+	if base == "" || base == "." || base == "/" {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return "", ""
+		}
+		base = path.Base(parsed.Path)
+		if base == "." || base == "/" {
+			return parsed.Hostname(), ""
+		}
+	}
+	ext := path.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	if name == "" {
+		// a hidden file like .bashrc
+		return base, ""
+	}
+	return name, strings.TrimPrefix(ext, ".")
+}
+
+func contentLength(h http.Header) int64 {
+	size, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64)
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,137 @@
+package universal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name               string
+		contentDisposition string
+		url                string
+		wantName           string
+		wantExt            string
+	}{
+		{name: "url path", url: "https://example.com/a/video.name.mp4?x=1", wantName: "video.name", wantExt: "mp4"},
+		{name: "escaped path", url: "https://example.com/%E8%A7%86%E9%A2%91.mp4", wantName: "视频", wantExt: "mp4"},
+		{name: "no extension", url: "https://example.com/post/w650", wantName: "w650"},
+		{name: "no path", url: "https://example.com/", wantName: "example.com"},
+		{
+			name:               "content disposition",
+			contentDisposition: `attachment; filename="report 2024.pdf"`,
+			url:                "https://example.com/download?id=1",
+			wantName:           "report 2024",
+			wantExt:            "pdf",
+		},
+		{
+			name:               "encoded content disposition",
+			contentDisposition: `attachment; filename*=UTF-8''%E8%A7%86%E9%A2%91.mp4`,
+			url:                "https://example.com/download?id=1",
+			wantName:           "视频",
+			wantExt:            "mp4",
+		},
+		{
+			name:               "content disposition with a path",
+			contentDisposition: `attachment; filename="..\..\evil.mp4"`,
+			url:                "https://example.com/download",
+			wantName:           "evil",
+			wantExt:            "mp4",
+		},
+		{
+			name:               "inline",
+			contentDisposition: "inline",
+			url:                "https://example.com/v.webm",
+			wantName:           "v",
+			wantExt:            "webm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, ext := fileName(tt.contentDisposition, tt.url)
+			if name != tt.wantName || ext != tt.wantExt {
+				t.Errorf("fileName() = %q, %q, want %q, %q", name, ext, tt.wantName, tt.wantExt)
+			}
+		})
+	}
+}
+
+func TestMimeDataType(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     extractors.DataType
+	}{
+		{mimeType: "video/mp4", want: extractors.DataTypeVideo},
+		{mimeType: "application/vnd.apple.mpegurl", want: extractors.DataTypeVideo},
+		{mimeType: "audio/mpeg", want: extractors.DataTypeAudio},
+		{mimeType: "image/webp", want: extractors.DataTypeImage},
+		{mimeType: "application/pdf", want: extractors.DataTypeFile},
+		{mimeType: "", want: extractors.DataTypeFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mimeType, func(t *testing.T) {
+			if got := mimeDataType(tt.mimeType); got != tt.want {
+				t.Errorf("mimeDataType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/range.mp4":
+			if r.Header.Get("Range") != "bytes=0-0" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Header().Set("Content-Range", "bytes 0-0/1234")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("0")) // nolint
+		case "/unknown-length":
+			w.Header().Set("Content-Type", "audio/mpeg; charset=binary")
+			w.Header().Set("Content-Length", "5678")
+			if r.Method == http.MethodHead {
+				return
+			}
+			w.Header().Set("Content-Range", "bytes 0-0/*")
+			w.Header().Set("Content-Length", "1")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("0")) // nolint
+		case "/download.php":
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Write([]byte("0123456789")) // nolint
+		case "/download":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", `attachment; filename="movie.mkv"`)
+			w.Write([]byte("0123456789")) // nolint
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want file
+	}{
+		{name: "range", path: "/range.mp4", want: file{mimeType: "video/mp4", size: 1234, name: "range", ext: "mp4"}},
+		{name: "head", path: "/unknown-length", want: file{mimeType: "audio/mpeg", size: 5678, name: "unknown-length", ext: "mp3"}},
+		{name: "ignored range", path: "/download", want: file{mimeType: "video/x-matroska", size: 10, name: "movie", ext: "mkv"}},
+		{name: "script", path: "/download.php", want: file{mimeType: "video/mp4", size: 10, name: "download", ext: "mp4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probe(nil, server.URL+tt.path, server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("probe() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
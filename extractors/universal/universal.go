@@ -4,12 +4,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
-	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("", New())
+	extractors.Register("", New(), extractors.CapabilityPlaylist, extractors.CapabilityImages, extractors.CapabilityAudio)
 }
 
 type extractor struct{}
@@ -21,36 +19,59 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	filename, ext, err := utils.GetNameAndExt(url)
+	f, err := probe(option.Client, url, url)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
 	}
-	size, err := request.Size(url, url)
-	if err != nil {
-		return nil, errors.WithStack(err)
+	if f.mimeType == "text/html" || f.mimeType == "application/xhtml+xml" {
+		// look for the media in the page, the page itself is downloaded if there is none
+		html, err := option.Client.Get(url, url, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		data, err := extractPage(url, html, option)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			return data, nil
+		}
+	}
+
+	// the segments of a streaming manifest are downloaded instead of the manifest itself
+	if f.ext == "m3u8" || f.ext == "mpd" {
+		stream, err := candidateStream(option.Client, candidate{url: url, dataType: extractors.DataTypeVideo, ext: f.ext}, url)
+		if err != nil {
+			return nil, err
+		}
+		return []*extractors.Data{
+			{
+				Site:    "Universal",
+				Title:   f.name,
+				Type:    extractors.DataTypeVideo,
+				Streams: map[string]*extractors.Stream{"default": stream},
+				URL:     url,
+			},
+		}, nil
 	}
+
 	streams := map[string]*extractors.Stream{
 		"default": {
 			Parts: []*extractors.Part{
 				{
 					URL:  url,
-					Size: size,
-					Ext:  ext,
+					Size: f.size,
+					Ext:  f.ext,
 				},
 			},
-			Size: size,
+			Size: f.size,
 		},
 	}
-	contentType, err := request.ContentType(url, url)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	return []*extractors.Data{
 		{
 			Site:    "Universal",
-			Title:   filename,
-			Type:    extractors.DataType(contentType),
+			Title:   f.name,
+			Type:    mimeDataType(f.mimeType),
 			Streams: streams,
 			URL:     url,
 		},
@@ -0,0 +1,72 @@
+package vimeo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/request"
+)
+
+const (
+	viewerAPI = "https://vimeo.com/_next/viewer"
+	loginURL  = "https://vimeo.com/log_in"
+	// sessionCookie is set by a successful login.
+	sessionCookie = "vimeo"
+)
+
+type viewer struct {
+	XSRFT string `json:"xsrft"`
+}
+
+// LoggedIn implements the extractors.Authenticator interface.
+func (e *extractor) LoggedIn(client *request.Client) bool {
+	for _, c := range client.Cookies("https://vimeo.com/") {
+		if c.Name == sessionCookie {
+			return true
+		}
+	}
+	return false
+}
+
+// Login implements the extractors.Authenticator interface, the username is the email address.
+func (e *extractor) Login(client *request.Client, username, password string) error {
+	data, err := client.GetByte(viewerAPI, loginURL, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var v viewer
+	if err = json.Unmarshal(data, &v); err != nil {
+		return errors.WithStack(err)
+	}
+	if v.XSRFT == "" {
+		return errors.New("vimeo login error: no xsrf token")
+	}
+
+	form := url.Values{
+		"action":   {"login"},
+		"email":    {username},
+		"password": {password},
+		"service":  {"vimeo"},
+		"token":    {v.XSRFT},
+	}
+	headers := map[string]string{
+		"Referer":      loginURL,
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+	res, err := client.Request(http.MethodPost, loginURL, strings.NewReader(form.Encode()), headers)
+	if err != nil {
+		if errors.Is(err, request.ErrUnauthorized) {
+			return errors.New("vimeo login error: wrong email or password")
+		}
+		return errors.WithStack(err)
+	}
+	res.Body.Close() // nolint
+	if !e.LoggedIn(client) {
+		return errors.New("vimeo login error: no session cookie")
+	}
+	return nil
+}
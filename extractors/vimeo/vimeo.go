@@ -7,12 +7,11 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("vimeo", New())
+	extractors.Register("vimeo", New(), extractors.CapabilityLogin)
 }
 
 type vimeoProgressive struct {
@@ -54,13 +53,13 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		err       error
 	)
 	if strings.Contains(url, "player.vimeo.com") {
-		html, err = request.Get(url, url, nil)
+		html, err = option.Client.Get(url, url, nil)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 	} else {
 		vid = utils.MatchOneOf(url, `vimeo\.com/(\d+)`)[1]
-		html, err = request.Get("https://player.vimeo.com/video/"+vid, url, nil)
+		html, err = option.Client.Get("https://player.vimeo.com/video/"+vid, url, nil)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -79,7 +78,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	streams := make(map[string]*extractors.Stream, len(vimeoData.Request.Files.Progressive))
 	var size int64
 	for _, video := range vimeoData.Request.Files.Progressive {
-		size, err = request.Size(video.URL, url)
+		size, err = option.Client.Size(video.URL, url)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
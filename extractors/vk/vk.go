@@ -7,7 +7,6 @@ import (
 
 	"github.com/iawia002/lux/config"
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -51,7 +50,7 @@ func (e extractor) Extract(url string, option extractors.Options) ([]*extractors
 	config.FakeHeaders["Cookie"] += "remixlang=0; remixaudio_show_alert_today=0; remixff=0; remixmdevice=1920/1080/1/!!-!!!!!!"
 
 	// Get html.
-	html, err := request.Get(url, url, config.FakeHeaders)
+	html, err := option.Client.Get(url, url, config.FakeHeaders)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -82,7 +81,7 @@ func (e extractor) Extract(url string, option extractors.Options) ([]*extractors
 	// Create download streams.
 	streams := make(map[string]*extractors.Stream)
 	for i := range srcs {
-		size, err := request.Size(srcs[i], "m.vk.vom")
+		size, err := option.Client.Size(srcs[i], "m.vk.vom")
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -36,11 +36,10 @@ type weiboData struct {
 	Msg  string   `json:"msg"`
 }
 
-func getXSRFToken() (string, error) {
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+func getXSRFToken(c *request.Client) (string, error) {
+	client := c.HTTPClient()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
 	}
 	url := "https://weibo.com/ajax/getversion"
 	req, err := http.NewRequest(http.MethodHead, url, nil)
@@ -66,7 +65,7 @@ func getXSRFToken() (string, error) {
 	return xsrfTokens[1], nil
 }
 
-func downloadWeiboVideo(url string) ([]*extractors.Data, error) {
+func downloadWeiboVideo(client *request.Client, url string) ([]*extractors.Data, error) {
 	urldata, err := netURL.Parse(url)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -75,7 +74,7 @@ func downloadWeiboVideo(url string) ([]*extractors.Data, error) {
 		"https://video.h5.weibo.cn/s/video/object?object_id=%s&mid=%s",
 		strings.Split(urldata.Path, "/")[1], strings.Split(urldata.Path, "/")[2],
 	)
-	jsonString, err := request.Get(api, "", nil)
+	jsonString, err := client.Get(api, "", nil)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -91,7 +90,7 @@ func downloadWeiboVideo(url string) ([]*extractors.Data, error) {
 		return nil, errors.WithStack(err)
 	}
 	realhdURL := strings.ReplaceAll(unescapedhdURL, `\/`, `/`)
-	hdsize, err := request.Size(realhdURL, "")
+	hdsize, err := client.Size(realhdURL, "")
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -113,7 +112,7 @@ func downloadWeiboVideo(url string) ([]*extractors.Data, error) {
 		return nil, errors.WithStack(err)
 	}
 	realURL := strings.ReplaceAll(unescapedURL, `\/`, `/`)
-	size, err := request.Size(realURL, "")
+	size, err := client.Size(realURL, "")
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -139,14 +138,14 @@ func downloadWeiboVideo(url string) ([]*extractors.Data, error) {
 	}, nil
 }
 
-func downloadWeiboTV(url string) ([]*extractors.Data, error) {
+func downloadWeiboTV(client *request.Client, url string) ([]*extractors.Data, error) {
 	APIEndpoint := "https://weibo.com/tv/api/component?page="
 	urldata, err := netURL.Parse(url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	APIURL := APIEndpoint + netURL.QueryEscape(urldata.Path)
-	token, err := getXSRFToken()
+	token, err := getXSRFToken(client)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -162,7 +161,7 @@ func downloadWeiboTV(url string) ([]*extractors.Data, error) {
 	oid := utils.MatchOneOf(url, `tv/show/([^?]+)`)[1]
 	postData := "data=" + netURL.QueryEscape("{\"Component_Play_Playinfo\":{\"oid\":\""+oid+"\"}}")
 	payload := strings.NewReader(postData)
-	res, err := request.Request(http.MethodPost, APIURL, payload, headers)
+	res, err := client.Request(http.MethodPost, APIURL, payload, headers)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -195,7 +194,7 @@ func downloadWeiboTV(url string) ([]*extractors.Data, error) {
 
 	streams := make(map[string]*extractors.Stream, len(realURLs))
 	for q, u := range realURLs {
-		size, err := request.Size(u, "")
+		size, err := client.Size(u, "")
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -233,13 +232,13 @@ func New() extractors.Extractor {
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
 	if !strings.Contains(url, "m.weibo.cn") {
 		if strings.Contains(url, "weibo.com/tv/show/") {
-			return downloadWeiboTV(url)
+			return downloadWeiboTV(option.Client, url)
 		} else if strings.Contains(url, "video.h5.weibo.cn") {
-			return downloadWeiboVideo(url)
+			return downloadWeiboVideo(option.Client, url)
 		}
 		url = strings.Replace(url, "weibo.com", "m.weibo.cn", 1)
 	}
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -268,7 +267,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	var size int64
 	for quality, realURL := range qualityUrls {
 		streamId := quality
-		size, err = request.Size(realURL, url)
+		size, err = option.Client.Size(realURL, url)
 		if err != nil {
 			continue
 		}
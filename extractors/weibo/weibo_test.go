@@ -9,7 +9,7 @@ import (
 
 func TestToken(t *testing.T) {
 	t.Run(
-		"XSRF token test", func(t *testing.T) { getXSRFToken() },
+		"XSRF token test", func(t *testing.T) { getXSRFToken(test.Client(t)) },
 	)
 }
 
@@ -46,7 +46,7 @@ func TestDownload(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			data, err := New().Extract(tt.args.URL, extractors.Options{})
+			data, err := New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 			test.CheckError(t, err)
 			test.Check(t, tt.args, data[0])
 		})
@@ -2,7 +2,6 @@ package xiaohongshu
 
 import (
 	"encoding/json"
-	neturl "net/url"
 	"strconv"
 	"strings"
 
@@ -10,12 +9,12 @@ import (
 
 	"github.com/iawia002/lux/config"
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
 	extractors.Register("xiaohongshu", New())
+	extractors.RegisterShortener("xhslink.com", nil)
 }
 
 type extractor struct{}
@@ -29,7 +28,7 @@ const mp4VideoType = "mp4"
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, config.FakeHeaders)
+	html, err := option.Client.Get(url, url, config.FakeHeaders)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -52,11 +51,6 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(extractors.ErrBodyParseFailed)
 	}
 
-	pUrl, err := neturl.ParseRequestURI(url)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	// streams
 	streams := make(map[string]*extractors.Stream)
 	var size int64
@@ -64,12 +58,12 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		if !strings.Contains(u, mp4VideoType) {
 			continue
 		}
-		size, err = request.Size(u, u)
+		size, err = option.Client.Size(u, u)
 		if err != nil {
 			continue
 		}
 
-		if pUrl.Host == "xhslink.com" && strings.Contains(u, "sns-video-qc") {
+		if strings.Contains(u, "sns-video-qc") {
 			size += 1 // Make sure the link is downloadable and sort the link first with the same size
 		}
 		streams[strconv.Itoa(i)] = &extractors.Stream{
@@ -7,12 +7,11 @@ import (
 
 	"github.com/iawia002/lux/extractors"
 	"github.com/iawia002/lux/parser"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
 func init() {
-	extractors.Register("ximalaya", New())
+	extractors.Register("ximalaya", New(), extractors.CapabilityAudio)
 }
 
 type extractor struct{}
@@ -24,7 +23,7 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -45,7 +44,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	}
 	itemId := itemIds[len(itemIds)-1]
 
-	jsonData, err := request.Get("https://www.ximalaya.com/revision/play/v1/audio?id="+itemId+"&ptype=1", url, nil)
+	jsonData, err := option.Client.Get("https://www.ximalaya.com/revision/play/v1/audio?id="+itemId+"&ptype=1", url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -56,11 +55,11 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 	realURL := ximalaya.Data.Src
 	urlData := make([]*extractors.Part, 0)
-	totalSize, err := request.Size(realURL, url)
+	totalSize, err := option.Client.Size(realURL, url)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	_, ext, err := utils.GetNameAndExt(realURL)
+	_, ext, err := utils.GetNameAndExt(option.Client, realURL)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
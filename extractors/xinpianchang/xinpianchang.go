@@ -10,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 )
 
 func init() {
@@ -40,7 +39,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		"User-Agent": "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0",
 	}
 
-	html, err := request.Get(url, url, headers)
+	html, err := option.Client.Get(url, url, headers)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -52,7 +51,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	appKey := r2.FindSubmatch([]byte(html))[1]
 
 	video_url := fmt.Sprintf("https://mod-api.xinpianchang.com/mod/api/v2/media/%s?appKey=%s", string(vid), string(appKey))
-	body, err := request.Get(video_url, url, headers)
+	body, err := option.Client.Get(video_url, url, headers)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -7,7 +7,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -47,7 +46,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	// generate api url
 	apiURL := genAPI(actionGetMvInfo, params)
 	var err error
-	html, err := request.Get(apiURL, url, nil)
+	html, err := option.Client.Get(apiURL, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -16,7 +16,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -92,7 +91,7 @@ func youkuUps(vid string, option extractors.Options) (*youkuData, error) {
 	if strings.Contains(option.Cookie, "cna") {
 		utids = utils.MatchOneOf(option.Cookie, `cna=(.+?);`, `cna\s+(.+?)\s`, `cna\s+(.+?)$`)
 	} else {
-		headers, err := request.Headers("http://log.mmstat.com/eg.js", youkuReferer)
+		headers, err := option.Client.Headers("http://log.mmstat.com/eg.js", youkuReferer)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -114,10 +113,15 @@ func youkuUps(vid string, option extractors.Options) (*youkuData, error) {
 			"https://ups.youku.com/ups/get.json?vid=%s&ccode=%s&client_ip=192.168.1.1&client_ts=%d&utid=%s&ckey=%s",
 			vid, ccode, time.Now().Unix()/1000, netURL.QueryEscape(utid), netURL.QueryEscape(option.YoukuCkey),
 		)
-		if option.YoukuPassword != "" {
-			url = fmt.Sprintf("%s&password=%s", url, option.YoukuPassword)
+		password := option.YoukuPassword
+		if password == "" {
+			// the password of a protected video
+			password = option.Password
 		}
-		html, err := request.GetByte(url, youkuReferer, nil)
+		if password != "" {
+			url = fmt.Sprintf("%s&password=%s", url, netURL.QueryEscape(password))
+		}
+		html, err := option.Client.GetByte(url, youkuReferer, nil)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
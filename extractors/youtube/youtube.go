@@ -3,9 +3,12 @@ package youtube
 import (
 	"fmt"
 	"net/http"
+	netURL "net/url"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kkdai/youtube/v2"
 	"github.com/pkg/errors"
@@ -16,15 +19,47 @@ import (
 )
 
 func init() {
-	e := New()
-	extractors.Register("youtube", e)
-	extractors.Register("youtu", e) // youtu.be
+	extractors.Register("youtube", New(), extractors.CapabilityPlaylist, extractors.CapabilityCaptions)
+	extractors.RegisterShortener("youtu.be", expandShortLink)
+}
+
+// expandShortLink expands https://youtu.be/<id> to the watch page without a request.
+func expandShortLink(u *netURL.URL) string {
+	id := strings.Trim(u.Path, "/")
+	if id == "" || strings.Contains(id, "/") {
+		return ""
+	}
+	q := u.Query()
+	q.Set("v", id)
+	return "https://www.youtube.com/watch?" + q.Encode()
 }
 
 const referer = "https://www.youtube.com"
 
+// channelPattern matches the channel pages: /channel/UCxxx, /@handle, /c/name and /user/name.
+var channelPattern = regexp.MustCompile(`youtube\.com/(?:channel/(UC[\w-]{22})|@[^/?#]+|c/[^/?#]+|user/[^/?#]+)`)
+
+// uploadsPlaylist returns the playlist of the uploads of the channel, newest first, its ID is the channel ID
+// with the "UU" prefix in place of "UC".
+func uploadsPlaylist(client *request.Client, url string, channel []string) (string, error) {
+	channelID := channel[1]
+	if channelID == "" {
+		html, err := client.Get(url, referer, nil)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		ids := utils.MatchOneOf(html, `"externalId":"(UC[\w-]{22})"`, `<meta itemprop="identifier" content="(UC[\w-]{22})"`)
+		if len(ids) < 2 {
+			return "", errors.New("unable to get the channel ID")
+		}
+		channelID = ids[1]
+	}
+	return "https://www.youtube.com/playlist?list=UU" + channelID[2:], nil
+}
+
 type extractor struct {
-	client *youtube.Client
+	client        *youtube.Client
+	requestClient *request.Client
 }
 
 // New returns a youtube extractor.
@@ -32,9 +67,7 @@ func New() extractors.Extractor {
 	return &extractor{
 		client: &youtube.Client{
 			HTTPClient: &http.Client{
-				Transport: &http.Transport{
-					Proxy: http.ProxyFromEnvironment,
-				},
+				Transport: request.Transport(),
 			},
 		},
 	}
@@ -42,7 +75,26 @@ func New() extractors.Extractor {
 
 // Extract is the main function to extract the data.
 func (e *extractor) Extract(url string, option extractors.Options) ([]*extractors.Data, error) {
-	if !option.Playlist {
+	if option.Client != nil {
+		// the extractor is shared, so the requests of this extraction use their own youtube client
+		e = &extractor{
+			client: &youtube.Client{
+				HTTPClient: &http.Client{
+					Transport: option.Client.Transport(),
+				},
+			},
+			requestClient: option.Client,
+		}
+	}
+
+	// a channel has no video of its own, it is always the playlist of its uploads
+	channel := channelPattern.FindStringSubmatch(url)
+	if channel != nil {
+		var err error
+		if url, err = uploadsPlaylist(option.Client, url, channel); err != nil {
+			return nil, err
+		}
+	} else if !option.Playlist {
 		video, err := e.client.GetVideo(url)
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -67,14 +119,23 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(err)
 	}
 
-	needDownloadItems := utils.NeedDownloadList(option.Items, option.ItemStart, option.ItemEnd, len(playlist.Videos))
+	videos := playlist.Videos
+	if channel != nil && option.OldestFirst {
+		videos = slices.Clone(videos)
+		slices.Reverse(videos)
+	}
+
+	needDownloadItems := utils.NeedDownloadList(option.Items, option.ItemStart, option.ItemEnd, len(videos))
 	extractedData := make([]*extractors.Data, len(needDownloadItems))
 	wgp := utils.NewWaitGroupPool(option.ThreadNumber)
 	dataIndex := 0
-	for index, videoEntry := range playlist.Videos {
+	for index, videoEntry := range videos {
 		if !slices.Contains(needDownloadItems, index+1) {
 			continue
 		}
+		if option.SkipItem != nil && option.SkipItem("https://www.youtube.com/watch?v="+videoEntry.ID) {
+			continue
+		}
 
 		wgp.Add()
 		go func(index int, entry *youtube.PlaylistEntry, extractedData []*extractors.Data) {
@@ -83,12 +144,12 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 			if err != nil {
 				return
 			}
-			extractedData[index] = e.youtubeDownload(url, video)
+			extractedData[index] = e.youtubeDownload("https://www.youtube.com/watch?v="+video.ID, video)
 		}(dataIndex, videoEntry, extractedData)
 		dataIndex++
 	}
 	wgp.Wait()
-	return extractedData, nil
+	return extractedData[:dataIndex], nil
 }
 
 // youtubeDownload download function for single url
@@ -156,8 +217,46 @@ func (e *extractor) youtubeDownload(url string, video *youtube.Video) *extractor
 		Type:     "video",
 		Streams:  streams,
 		Captions: captions,
+		Chapters: descriptionChapters(video.Description, video.Duration),
 		URL:      url,
+		ID:       video.ID,
+	}
+}
+
+// chapterPattern matches a chapter line of the description, eg: "1:02:03 Title" or "(02:03) - Title".
+var chapterPattern = regexp.MustCompile(`^\s*\(?((?:\d+:)?\d{1,2}:\d{2})\)?\s*[-–—:|]?\s*(.+?)\s*$`)
+
+// descriptionChapters returns the chapters in the description, YouTube shows them only if the first one starts
+// at 0:00, and there are at least 3 chapters in ascending order.
+func descriptionChapters(description string, duration time.Duration) []extractors.Chapter {
+	var chapters []extractors.Chapter
+	for _, line := range strings.Split(description, "\n") {
+		m := chapterPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var start float64
+		for _, n := range strings.Split(m[1], ":") {
+			v, _ := strconv.Atoi(n)
+			start = start*60 + float64(v)
+		}
+		if len(chapters) == 0 && start != 0 {
+			return nil
+		}
+		if len(chapters) > 0 {
+			if start <= chapters[len(chapters)-1].Start {
+				return nil
+			}
+			chapters[len(chapters)-1].End = start
+		}
+		chapters = append(chapters, extractors.Chapter{Start: start, Title: m[2]})
+	}
+	// the last chapter ends at the end of the video, the chapters are left out if the duration is unknown
+	if len(chapters) < 3 || duration.Seconds() <= chapters[len(chapters)-1].Start {
+		return nil
 	}
+	chapters[len(chapters)-1].End = duration.Seconds()
+	return chapters
 }
 
 func (e *extractor) genPartByFormat(video *youtube.Video, f *youtube.Format) (*extractors.Part, error) {
@@ -168,7 +267,7 @@ func (e *extractor) genPartByFormat(video *youtube.Video, f *youtube.Format) (*e
 	}
 	size := f.ContentLength
 	if size == 0 {
-		size, _ = request.Size(url, referer)
+		size, _ = e.requestClient.Size(url, referer)
 	}
 	return &extractors.Part{
 		URL:  url,
@@ -1,9 +1,14 @@
 package youtube
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/test"
 )
 
@@ -59,15 +64,89 @@ func TestYoutube(t *testing.T) {
 			if tt.playlist {
 				// playlist mode
 				_, err = New().Extract(tt.args.URL, extractors.Options{
+					Client:       test.Client(t),
 					Playlist:     true,
 					ThreadNumber: 9,
 				})
 				test.CheckError(t, err)
 			} else {
-				data, err = New().Extract(tt.args.URL, extractors.Options{})
+				data, err = New().Extract(tt.args.URL, extractors.Options{Client: test.Client(t)})
 				test.CheckError(t, err)
 				test.Check(t, tt.args, data[0])
 			}
 		})
 	}
 }
+
+func TestUploadsPlaylist(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<script>var ytInitialData = {"metadata":{"channelMetadataRenderer":{"externalId":"UCuAXFkgsw1L7xaCfnd5JJOw"}}};</script>`)) // nolint
+	}))
+	defer page.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "channel ID",
+			url:  "https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw/videos",
+			want: "https://www.youtube.com/playlist?list=UUuAXFkgsw1L7xaCfnd5JJOw",
+		},
+		{
+			name: "handle",
+			url:  "https://www.youtube.com/@RickAstleyYT",
+			want: "https://www.youtube.com/playlist?list=UUuAXFkgsw1L7xaCfnd5JJOw",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := channelPattern.FindStringSubmatch(tt.url)
+			if channel == nil {
+				t.Fatalf("%s is not a channel", tt.url)
+			}
+			// the page of the handle is served locally
+			got, err := uploadsPlaylist(request.DefaultClient(), page.URL, channel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("uploadsPlaylist() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriptionChapters(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		duration    time.Duration
+		want        []extractors.Chapter
+	}{
+		{
+			name:        "chapters",
+			description: "Tracklist:\n0:00 Intro\n(1:30) - Verse: one\n1:02:03 Outro\nhttps://example.com",
+			duration:    4000 * time.Second,
+			want: []extractors.Chapter{
+				{Start: 0, End: 90, Title: "Intro"},
+				{Start: 90, End: 3723, Title: "Verse: one"},
+				{Start: 3723, End: 4000, Title: "Outro"},
+			},
+		},
+		{name: "not from 0:00", description: "0:10 a\n0:20 b\n0:30 c", duration: 4000 * time.Second},
+		{name: "too few", description: "0:00 a\n0:20 b", duration: 4000 * time.Second},
+		{name: "not in order", description: "0:00 a\n0:20 b\n0:10 c", duration: 4000 * time.Second},
+		{name: "unknown duration", description: "0:00 a\n0:20 b\n0:30 c"},
+		{name: "past the end", description: "0:00 a\n0:20 b\n0:30 c", duration: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := descriptionChapters(tt.description, tt.duration)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("descriptionChapters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
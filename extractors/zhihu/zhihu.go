@@ -8,7 +8,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/iawia002/lux/extractors"
-	"github.com/iawia002/lux/request"
 	"github.com/iawia002/lux/utils"
 )
 
@@ -32,7 +31,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		return nil, errors.WithStack(extractors.ErrURLParseFailed)
 	}
 
-	html, err := request.Get(url, url, nil)
+	html, err := option.Client.Get(url, url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -49,7 +48,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 		title = titleMatch[1]
 	}
 
-	resp, err := request.GetByte(fmt.Sprintf("%s%s", api, videoID[1]), url, nil)
+	resp, err := option.Client.GetByte(fmt.Sprintf("%s%s", api, videoID[1]), url, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -22,8 +22,8 @@ import (
 
 func init() {
 	zingmp3Extractor := New()
-	extractors.Register("zingmp3", zingmp3Extractor)
-	extractors.Register("zing", zingmp3Extractor)
+	extractors.Register("zingmp3", zingmp3Extractor, extractors.CapabilityAudio)
+	extractors.Register("zing", zingmp3Extractor, extractors.CapabilityAudio)
 }
 
 type extractor struct{}
@@ -54,23 +54,23 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	}
 	urlType := urlMatcher[1]
 	id := urlMatcher[2]
-	if err := updatingCookies(); err != nil {
+	if err := updatingCookies(option.Client); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	data := callApi(urlType, params{"id": id})
+	data := callApi(option.Client, urlType, params{"id": id})
 	title, _ := jsonparser.GetString(data, "title")
 	var contentType extractors.DataType
 	var source []byte
 	if urlType == "video-clip" {
 		source, _, _, _ = jsonparser.Get(data, "streaming")
 		api := fmt.Sprintf(`http://api.mp3.zing.vn/api/mobile/video/getvideoinfo?requestdata={"id":"%s"}`, id)
-		res, _ := request.Get(api, api, nil)
+		res, _ := option.Client.Get(api, api, nil)
 		newSource, _, _, _ := jsonparser.Get([]byte(res), "source")
 		source, _ = jsonparser.Set(source, newSource, "mp4")
 		contentType = extractors.DataTypeVideo
 	} else {
 		contentType = extractors.DataTypeAudio
-		source = callApi("song-streaming", params{"id": id})
+		source = callApi(option.Client, "song-streaming", params{"id": id})
 	}
 	streams := make(map[string]*extractors.Stream)
 	if err := jsonparser.ObjectEach(source, func(k []byte, v []byte, dataType jsonparser.ValueType, offset int) error {
@@ -82,7 +82,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 
 		// Handle for audio
 		if key != "mp4" && key != "hls" {
-			size, _ := request.Size(value, url)
+			size, _ := option.Client.Size(value, url)
 			urlData := &extractors.Part{
 				URL:  value,
 				Ext:  "mp3",
@@ -102,7 +102,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 				return nil
 			}
 			if resolution == "hls" {
-				urls, _ := utils.M3u8URLs(videoUrl)
+				urls, _ := utils.M3u8URLs(option.Client, videoUrl)
 				parts := make([]*extractors.Part, 0)
 				for _, u := range urls {
 					parts = append(parts, &extractors.Part{
@@ -117,7 +117,7 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 				}
 				return nil
 			}
-			size, _ := request.Size(videoUrl, url)
+			size, _ := option.Client.Size(videoUrl, url)
 			streams[fmt.Sprintf("mp4-%s", resolution)] = &extractors.Stream{
 				Parts: []*extractors.Part{{
 					URL:  videoUrl,
@@ -142,19 +142,19 @@ func (e *extractor) Extract(url string, option extractors.Options) ([]*extractor
 	}, nil
 }
 
-func callApi(urlType string, p params) []byte {
+func callApi(client *request.Client, urlType string, p params) []byte {
 	api := generateApi(urlType, p)
-	res, _ := request.GetByte(api, api, nil)
+	res, _ := client.GetByte(api, api, nil)
 	data, _, _, _ := jsonparser.Get(res, "data")
 	return data
 }
 
-func updatingCookies() error {
+func updatingCookies(client *request.Client) error {
 	// For the first time. We need to call the temp API to get cookies and set cookies to for next request
 	// But sometime zingmp3 doesn't return cookies. We need to retry get and set cookies again (only allow 5 time)
 	for i := 0; i < 5; i++ {
 		api := generateApi("bai-hat", params{"id": ""})
-		res, err := request.Request(http.MethodGet, api, nil, nil)
+		res, err := client.Request(http.MethodGet, api, nil, nil)
 		if err != nil {
 			return err
 		}
@@ -3,8 +3,8 @@ module github.com/iawia002/lux
 go 1.24
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/EDDYCJY/fake-useragent v0.2.0
-	github.com/MercuryEngineering/CookieMonster v0.0.0-20180304172713-1584578b3403
 	github.com/PuerkitoBio/goquery v1.10.3
 	github.com/buger/jsonparser v1.1.1
 	github.com/cheggaaa/pb/v3 v3.1.7
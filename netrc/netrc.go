@@ -0,0 +1,132 @@
+package netrc
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Machine is the login information of a machine in a .netrc file.
+type Machine struct {
+	// Name is empty for the default entry.
+	Name     string
+	Login    string
+	Password string
+	Account  string
+}
+
+// DefaultPath returns the path of the .netrc file, $NETRC takes precedence over the one in the home directory.
+func DefaultPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(home, name)
+}
+
+// Parse parses the entries of a .netrc file, macros are skipped.
+func Parse(r io.Reader) ([]*Machine, error) {
+	var (
+		machines []*Machine
+		current  *Machine
+		tokens   []string
+	)
+	scanner := bufio.NewScanner(r)
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			// a macro definition ends with an empty line
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			if field == "macdef" {
+				inMacro = true
+				tokens = append(tokens, fields[i:min(i+2, len(fields))]...)
+				break
+			}
+			tokens = append(tokens, field)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "default" {
+			current = &Machine{}
+			machines = append(machines, current)
+			continue
+		}
+		if i+1 >= len(tokens) {
+			return nil, errors.Errorf("missing value of %q in .netrc", token)
+		}
+		i++
+		value := tokens[i]
+		switch token {
+		case "machine":
+			current = &Machine{Name: value}
+			machines = append(machines, current)
+		case "login", "password", "account":
+			if current == nil {
+				return nil, errors.Errorf("%q is not in a machine entry in .netrc", token)
+			}
+			switch token {
+			case "login":
+				current.Login = value
+			case "password":
+				current.Password = value
+			default:
+				current.Account = value
+			}
+		case "macdef":
+		default:
+			return nil, errors.Errorf("unknown token %q in .netrc", token)
+		}
+	}
+	return machines, nil
+}
+
+// Lookup returns the entry of the machine in the .netrc file, or the default entry if there is no such machine.
+// It returns nil if neither exists.
+func Lookup(path, name string) (*Machine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close() // nolint
+	machines, err := Parse(f)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	var fallback *Machine
+	for _, m := range machines {
+		if m.Name == name {
+			return m, nil
+		}
+		if m.Name == "" && fallback == nil {
+			fallback = m
+		}
+	}
+	return fallback, nil
+}
@@ -0,0 +1,77 @@
+package netrc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testNetrc = `# accounts
+machine vimeo login alice@example.com password "p@ss
+machine geekbang
+	login 13800000000
+	password secret # the phone number
+	account personal
+
+macdef init
+machine fake login fake password fake
+
+default login anonymous password guest
+`
+
+func TestParse(t *testing.T) {
+	machines, err := Parse(strings.NewReader(testNetrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Machine{
+		{Name: "vimeo", Login: "alice@example.com", Password: `"p@ss`},
+		{Name: "geekbang", Login: "13800000000", Password: "secret", Account: "personal"},
+		{Login: "anonymous", Password: "guest"},
+	}
+	if len(machines) != len(want) {
+		t.Fatalf("got %d machines, want %d", len(machines), len(want))
+	}
+	for i, m := range machines {
+		if *m != want[i] {
+			t.Errorf("machine %d = %+v, want %+v", i, *m, want[i])
+		}
+	}
+
+	for _, invalid := range []string{"machine", "login alice", "machine vimeo user alice"} {
+		if _, err = Parse(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Parse(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".netrc")
+	if err := os.WriteFile(path, []byte(testNetrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		login string
+	}{
+		{name: "geekbang", login: "13800000000"},
+		{name: "youtube", login: "anonymous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Lookup(path, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m == nil || m.Login != tt.login {
+				t.Errorf("Lookup(%s) = %+v, want login %s", tt.name, m, tt.login)
+			}
+		})
+	}
+
+	t.Setenv("NETRC", path)
+	if got := DefaultPath(); got != path {
+		t.Errorf("DefaultPath() = %s, want %s", got, path)
+	}
+}
@@ -0,0 +1,257 @@
+package request
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CassetteMode tells a Recorder whether to record or replay the requests.
+type CassetteMode int
+
+const (
+	// ModeReplay serves the recorded responses without sending any request,
+	// a request that has not been recorded fails.
+	ModeReplay CassetteMode = iota
+	// ModeRecord sends the requests and records the responses, Save writes them to the cassette.
+	ModeRecord
+)
+
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Range is part of the request, Size asks for the first byte of the same URL that Get fetches.
+	Range string `json:"range,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	// Base64 is true if the body is not valid UTF-8 and is encoded in base64.
+	Base64 bool `json:"base64,omitempty"`
+}
+
+// volatileParams are the query parameters that change on every request of the sites, eg: timestamps and
+// signatures, the requests are matched without them.
+var volatileParams = map[string][]string{
+	// the WBI signature of the API
+	"bilibili.com": {"wts", "w_rid"},
+	// the signed stream URLs
+	"googlevideo.com": {"expire", "ei", "ip", "id", "sig", "lsig", "signature", "sparams", "lsparams", "n", "pot", "cpn", "rqh"},
+}
+
+// scrubbedHeaders are the response headers that are not written to the cassettes, they carry the session
+// and the credentials of the account that recorded them.
+var scrubbedHeaders = []string{"Set-Cookie", "Set-Cookie2", "Authorization", "Proxy-Authorization", "WWW-Authenticate", "Authentication-Info"}
+
+// cassetteURL returns the URL that the requests are matched by, without the volatile parameters of its site.
+func cassetteURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	host := strings.ToLower(u.Hostname())
+	for site, params := range volatileParams {
+		if host != site && !strings.HasSuffix(host, "."+site) {
+			continue
+		}
+		query := u.Query()
+		for _, p := range params {
+			query.Del(p)
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// match reports whether the recorded request is the same request as the key.
+func (r cassetteRequest) match(key cassetteRequest) bool {
+	return r.Method == key.Method && r.Range == key.Range && r.Body == key.Body && cassetteURL(r.URL) == cassetteURL(key.URL)
+}
+
+// Recorder is an http.RoundTripper that records the requests and responses in a cassette file,
+// so that tests can replay them later without network access.
+// Identical requests are replayed in the recorded order, the last response is repeated once they run out.
+// The volatile query parameters of the sites are ignored when matching, the session headers are not recorded.
+type Recorder struct {
+	path string
+	mode CassetteMode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*interaction
+	replayed     map[*interaction]bool
+}
+
+// NewRecorder returns a Recorder of the cassette file, next sends the requests in record mode,
+// http.DefaultTransport is used if it is nil.
+func NewRecorder(path string, mode CassetteMode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		next:     next,
+		replayed: make(map[*interaction]bool),
+	}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var c cassette
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, errors.Wrapf(err, "invalid cassette %s", path)
+	}
+	r.interactions = c.Interactions
+	return r, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := cassetteRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Range:  req.Header.Get("Range"),
+		Body:   body,
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, key)
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close() // nolint
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	header := res.Header.Clone()
+	for _, h := range scrubbedHeaders {
+		header.Del(h)
+	}
+	recorded := cassetteResponse{
+		StatusCode: res.StatusCode,
+		Header:     header,
+	}
+	if utf8.Valid(data) {
+		recorded.Body = string(data)
+	} else {
+		recorded.Body = base64.StdEncoding.EncodeToString(data)
+		recorded.Base64 = true
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, &interaction{Request: key, Response: recorded})
+	r.mu.Unlock()
+
+	res.Body = io.NopCloser(bytes.NewReader(data))
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, key cassetteRequest) (*http.Response, error) {
+	r.mu.Lock()
+	var found *interaction
+	for _, i := range r.interactions {
+		if !i.Request.match(key) {
+			continue
+		}
+		found = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if found != nil {
+		r.replayed[found] = true
+	}
+	r.mu.Unlock()
+
+	if found == nil {
+		return nil, errors.Errorf("%s %s is not recorded in cassette %s", key.Method, key.URL, r.path)
+	}
+	body := []byte(found.Response.Body)
+	if found.Response.Base64 {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(found.Response.Body); err != nil {
+			return nil, errors.Wrapf(err, "invalid cassette %s", r.path)
+		}
+	}
+	header := found.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", found.Response.StatusCode, http.StatusText(found.Response.StatusCode)),
+		StatusCode:    found.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the cassette file, it does nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(r.path, append(data, '\n'), 0644))
+}
+
+// readRequestBody reads the body of the request and puts it back, so it can still be sent.
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close() // nolint
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
@@ -0,0 +1,139 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/binary":
+			w.Write([]byte{0xff, 0xfe, 0x00, 0x01}) // nolint
+		case "/post":
+			body, _ := io.ReadAll(r.Body)
+			w.Write([]byte("got " + string(body))) // nolint
+		case "/counter":
+			w.Header().Set("X-Hits", strings.Repeat("x", hits))
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.Write([]byte(strings.Repeat("x", hits))) // nolint
+		default:
+			http.ServeContent(w, r, "video.mp4", time.Time{}, strings.NewReader("0123456789"))
+		}
+	}))
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	type result struct {
+		get, binary, post, first, second, last string
+		size                                   int64
+	}
+	run := func(c *Client) result {
+		var r result
+		var err error
+		if r.get, err = c.Get(server.URL+"/video.mp4", "", nil); err != nil {
+			t.Fatal(err)
+		}
+		if r.size, err = c.Size(server.URL+"/video.mp4", ""); err != nil {
+			t.Fatal(err)
+		}
+		if r.binary, err = c.Get(server.URL+"/binary", "", nil); err != nil {
+			t.Fatal(err)
+		}
+		res, err := c.Request(http.MethodPost, server.URL+"/post", strings.NewReader("data"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close() // nolint
+		r.post = string(body)
+		for _, s := range []*string{&r.first, &r.second} {
+			if *s, err = c.Get(server.URL+"/counter", "", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		h, err := c.Headers(server.URL+"/counter", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.last = h.Get("X-Hits")
+		return r
+	}
+
+	recorder, err := NewRecorder(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(Options{RetryTimes: 1, Transport: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := run(c)
+	if err = recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	replayer, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = New(Options{RetryTimes: 1, Transport: replayer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := run(c)
+	if recorded != replayed {
+		t.Errorf("replayed %+v, recorded %+v", replayed, recorded)
+	}
+	if recorded.size != 10 || recorded.binary != "\xff\xfe\x00\x01" || recorded.post != "got data" || recorded.first == recorded.second {
+		t.Errorf("unexpected responses %+v", recorded)
+	}
+
+	// the last response is repeated once the recorded ones run out
+	if body, err := c.Get(server.URL+"/counter", "", nil); err != nil || body != recorded.last {
+		t.Errorf("the last response %q is not repeated, got %q, %v", recorded.last, body, err)
+	}
+	if _, err = c.Get(server.URL+"/unknown", "", nil); err == nil || !strings.Contains(err.Error(), "is not recorded") {
+		t.Errorf("expected an error for a request that is not recorded, got %v", err)
+	}
+	if _, err = NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil); err == nil {
+		t.Error("replaying a missing cassette should fail")
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "secret") {
+		t.Error("the session cookie is written to the cassette")
+	}
+}
+
+func TestCassetteURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{
+			url:  "https://api.bilibili.com/x/player/wbi/playurl?bvid=BV1xx&w_rid=abc&wts=1700000000",
+			want: "https://api.bilibili.com/x/player/wbi/playurl?bvid=BV1xx",
+		},
+		{
+			url:  "https://rr1---sn-a.googlevideo.com/videoplayback?expire=1700000000&itag=18&sig=xyz",
+			want: "https://rr1---sn-a.googlevideo.com/videoplayback?itag=18",
+		},
+		{
+			// the parameters only belong to their sites
+			url:  "https://www.example.com/video?wts=1&sig=2",
+			want: "https://www.example.com/video?wts=1&sig=2",
+		},
+	}
+	for _, tt := range tests {
+		if got := cassetteURL(tt.url); got != tt.want {
+			t.Errorf("cassetteURL(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultConnectTimeout        = 30 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultResponseHeaderTimeout = time.Minute
+	defaultStallTimeout          = time.Minute
+	// defaultMaxIdleConnsPerHost is large enough for the default download thread number,
+	// http.DefaultMaxIdleConnsPerHost (2) would close most connections after each request.
+	defaultMaxIdleConnsPerHost = 32
+)
+
+func durationOrDefault(d, defaultValue time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultValue
+	}
+	return d
+}
+
+// tlsConfig returns the TLS configuration, certificates are verified unless NoCheckCertificate is set.
+func tlsConfig(opt Options) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: opt.NoCheckCertificate, // nolint:gosec
+	}
+	if opt.CACert != "" {
+		pem, err := os.ReadFile(opt.CACert)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		// trust the given CA in addition to the system ones, eg: the CA of a corporate proxy
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in %s", opt.CACert)
+		}
+		config.RootCAs = pool
+	}
+	if opt.ClientCert != "" {
+		// the private key can be in the same file as the certificate
+		keyFile := opt.ClientKey
+		if keyFile == "" {
+			keyFile = opt.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(opt.ClientCert, keyFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	} else if opt.ClientKey != "" {
+		return nil, errors.New("the client key requires a client certificate")
+	}
+	return config, nil
+}
+
+// newHTTPClient returns a long-lived http.Client configured by the TLS, timeout and connection options,
+// the requests are sent through the proxy unless a proxy rule matches.
+func newHTTPClient(opt Options, proxy string, jar http.CookieJar) (*http.Client, error) {
+	if opt.Transport != nil {
+		return &http.Client{
+			Transport: opt.Transport,
+			Jar:       jar,
+		}, nil
+	}
+	tlsClientConfig, err := tlsConfig(opt)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseProxyRules(opt.ProxyRules)
+	if err != nil {
+		return nil, err
+	}
+	proxyFunc, err := newProxyFunc(proxy, rules)
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout := durationOrDefault(opt.ConnectTimeout, defaultConnectTimeout)
+	maxIdleConnsPerHost := defaultMaxIdleConnsPerHost
+	if opt.MaxConnsPerHost > 0 && opt.MaxConnsPerHost < maxIdleConnsPerHost {
+		maxIdleConnsPerHost = opt.MaxConnsPerHost
+	}
+	transport := &http.Transport{
+		Proxy: proxyFunc,
+		DialContext: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		DisableCompression:    true,
+		TLSHandshakeTimeout:   connectTimeout,
+		TLSClientConfig:       tlsClientConfig,
+		IdleConnTimeout:       durationOrDefault(opt.IdleConnTimeout, defaultIdleConnTimeout),
+		ResponseHeaderTimeout: durationOrDefault(opt.ResponseHeaderTimeout, defaultResponseHeaderTimeout),
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		MaxConnsPerHost:       opt.MaxConnsPerHost,
+	}
+	return &http.Client{
+		Transport: transport,
+		Jar:       jar,
+	}, nil
+}
+
+// Transport returns the http.RoundTripper of the client,
+// so the code that needs its own http.Client uses the same settings.
+func (c *Client) Transport() http.RoundTripper {
+	return c.client().httpClient.Transport
+}
+
+// DirectTransport returns a transport with the settings of the client that sends the requests without a proxy,
+// eg: to a service on the local network. The caller closes its idle connections once done.
+func (c *Client) DirectTransport() http.RoundTripper {
+	transport, ok := c.Transport().(*http.Transport)
+	if !ok {
+		// a transport given by the options, eg: in tests
+		return c.Transport()
+	}
+	direct := transport.Clone()
+	direct.Proxy = nil
+	return direct
+}
+
+// HTTPClient returns an http.Client with the transport, the stall timeout, the cookie jar and the cookie option
+// of the client, for the libraries that send the requests with an http.Client of their own, eg: colly.
+func (c *Client) HTTPClient() *http.Client {
+	c = c.client()
+	return &http.Client{
+		Transport: &clientTransport{c: c},
+		Jar:       c.jar,
+	}
+}
+
+// clientTransport sends the requests of HTTPClient.
+type clientTransport struct {
+	c *Client
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.c.rawCookie != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Cookie", t.c.rawCookie)
+	}
+	return doWithStallTimeout(t.c.Transport().RoundTrip, req, t.c.stallTimeout)
+}
+
+// Transport returns an http.RoundTripper backed by the transport of the default client,
+// it follows the options set by SetOptions.
+func Transport() http.RoundTripper {
+	return defaultTransport{}
+}
+
+type defaultTransport struct{}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (defaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return DefaultClient().Transport().RoundTrip(req)
+}
+
+// doWithStallTimeout sends the request and aborts it once no data has been received for the stall timeout,
+// unlike http.Client.Timeout, a large file is never aborted as long as the download keeps making progress.
+func doWithStallTimeout(do func(*http.Request) (*http.Response, error), req *http.Request, timeout time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	s := &stallReader{
+		cancel:  cancel,
+		timeout: timeout,
+	}
+	s.timer = time.AfterFunc(s.timeout, s.stall)
+
+	res, err := do(req.WithContext(ctx))
+	if err != nil {
+		s.timer.Stop()
+		cancel()
+		if s.stalled.Load() {
+			return nil, errors.WithStack(&stallError{received: "response", timeout: s.timeout})
+		}
+		return nil, err
+	}
+	s.ReadCloser = res.Body
+	res.Body = s
+	return res, nil
+}
+
+// stallError is returned when nothing has been received for the stall timeout.
+type stallError struct {
+	received string
+	timeout  time.Duration
+}
+
+func (e *stallError) Error() string {
+	return fmt.Sprintf("no %s received for %s", e.received, e.timeout)
+}
+
+// stallReader cancels the request if Read makes no progress for the timeout.
+type stallReader struct {
+	io.ReadCloser
+	timer   *time.Timer
+	timeout time.Duration
+	cancel  context.CancelFunc
+	stalled atomic.Bool
+}
+
+func (s *stallReader) stall() {
+	s.stalled.Store(true)
+	s.cancel()
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if n > 0 {
+		s.timer.Reset(s.timeout)
+	}
+	if err != nil && err != io.EOF && s.stalled.Load() {
+		return n, errors.WithStack(&stallError{received: "data", timeout: s.timeout})
+	}
+	return n, err
+}
+
+func (s *stallReader) Close() error {
+	s.timer.Stop()
+	s.cancel()
+	return s.ReadCloser.Close()
+}
@@ -0,0 +1,289 @@
+package request
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStallTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			// keeps making progress, takes longer than the stall timeout in total
+			for i := 0; i < 4; i++ {
+				w.Write([]byte("data")) // nolint
+				w.(http.Flusher).Flush()
+				time.Sleep(100 * time.Millisecond)
+			}
+			return
+		}
+		w.Write([]byte("data")) // nolint
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	SetOptions(Options{RetryTimes: 1, StallTimeout: 300 * time.Millisecond})
+	defer SetOptions(Options{})
+
+	body, err := Get(server.URL+"/slow", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body != strings.Repeat("data", 4) {
+		t.Errorf("got %q", body)
+	}
+
+	res, err := Request(http.MethodGet, server.URL+"/stall", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close() // nolint
+	_, err = io.ReadAll(res.Body)
+	if err == nil || !strings.Contains(err.Error(), "no data received") {
+		t.Errorf("expected stall error, got %v", err)
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "video.mp4", time.Time{}, strings.NewReader(strings.Repeat("x", 100000)))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	SetOptions(Options{RetryTimes: 1})
+	for i := 0; i < 10; i++ {
+		size, err := Size(server.URL+"/video.mp4", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != 100000 {
+			t.Errorf("Size() = %d, want %d", size, 100000)
+		}
+	}
+	if n := atomic.LoadInt32(&connections); n != 1 {
+		t.Errorf("%d connections were opened, want 1", n)
+	}
+}
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTLSVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok")) // nolint
+	}))
+	defer server.Close()
+	defer SetOptions(Options{})
+	caCert := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	tests := []struct {
+		name    string
+		opt     Options
+		wantErr bool
+	}{
+		{name: "verify by default", opt: Options{RetryTimes: 1}, wantErr: true},
+		{name: "no check certificate", opt: Options{RetryTimes: 1, NoCheckCertificate: true}},
+		{name: "custom CA", opt: Options{RetryTimes: 1, CACert: caCert}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetOptions(tt.opt); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Get(server.URL, "", nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := SetOptions(Options{CACert: writePEM(t, "empty.pem", "EMPTY", nil)}); err == nil {
+		t.Error("SetOptions() should fail with an invalid CA file")
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "lux"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert := writePEM(t, "client.pem", "CERTIFICATE", der)
+	clientKey := writePEM(t, "client.key", "PRIVATE KEY", keyDER)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName)) // nolint
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+	defer SetOptions(Options{})
+
+	if err := SetOptions(Options{RetryTimes: 1, NoCheckCertificate: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get(server.URL, "", nil); err == nil {
+		t.Error("request without a client certificate should fail")
+	}
+
+	if err := SetOptions(Options{RetryTimes: 1, NoCheckCertificate: true, ClientCert: clientCert, ClientKey: clientKey}); err != nil {
+		t.Fatal(err)
+	}
+	body, err := Get(server.URL, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "lux" {
+		t.Errorf("got %q, want %q", body, "lux")
+	}
+
+	// the shared transport is used by code that builds its own http.Client
+	res, err := (&http.Client{Transport: Transport()}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close() // nolint
+}
+
+func TestClientIsolation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: r.URL.Query().Get("user")})
+			return
+		}
+		w.Write([]byte(r.Header.Get("User-Agent") + "|" + r.Header.Get("Cookie"))) // nolint
+	}))
+	defer server.Close()
+
+	alice, err := New(Options{RetryTimes: 1, UserAgent: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := New(Options{RetryTimes: 1, UserAgent: "bob", Cookie: "token=bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alice.Get(server.URL+"/login?user=alice", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	// shares the cookie jar of alice
+	carol, err := alice.WithOptions(Options{RetryTimes: 1, UserAgent: "carol"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		client *Client
+		want   string
+	}{
+		{name: "alice", client: alice, want: "alice|session=alice"},
+		{name: "bob", client: bob, want: "bob|token=bob"},
+		{name: "carol", client: carol, want: "carol|session=alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.client.Get(server.URL, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if cookies := Cookies(server.URL); len(cookies) != 0 {
+		t.Errorf("the default client got cookies %v", cookies)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "alice"})
+		case "/stall":
+			w.Write([]byte("data")) // nolint
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.Write([]byte(r.Header.Get("Cookie"))) // nolint
+		}
+	}))
+	defer server.Close()
+
+	get := func(c *http.Client, url string) (string, error) {
+		res, err := c.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close() // nolint
+		body, err := io.ReadAll(res.Body)
+		return string(body), err
+	}
+
+	c, err := New(Options{RetryTimes: 1, StallTimeout: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Get(server.URL+"/login", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	// the session of the cookie jar is shared
+	if got, err := get(c.HTTPClient(), server.URL); err != nil || got != "session=alice" {
+		t.Errorf("got %q, %v, want the session cookie", got, err)
+	}
+	if _, err = get(c.HTTPClient(), server.URL+"/stall"); err == nil || !strings.Contains(err.Error(), "no data received") {
+		t.Errorf("expected stall error, got %v", err)
+	}
+
+	bob, err := New(Options{RetryTimes: 1, Cookie: "token=bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := get(bob.HTTPClient(), server.URL); err != nil || got != "token=bob" {
+		t.Errorf("got %q, %v, want the cookie option", got, err)
+	}
+}
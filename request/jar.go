@@ -0,0 +1,276 @@
+package request
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const httpOnlyPrefix = "#HttpOnly_"
+
+// cookieJar is an http.CookieJar that also keeps track of every cookie it holds,
+// so that the cookies can be saved to a Netscape cookie file and survive across runs.
+type cookieJar struct {
+	mu  sync.Mutex
+	jar *cookiejar.Jar
+	// domain;path;name -> cookie, the domain of a cookie that matches subdomains starts with a dot
+	entries map[string]*http.Cookie
+}
+
+func newCookieJar() *cookieJar {
+	jar, _ := cookiejar.New(nil)
+	return &cookieJar{
+		jar:     jar,
+		entries: make(map[string]*http.Cookie),
+	}
+}
+
+// SetCookies implements the http.CookieJar interface.
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+	now := time.Now()
+	for _, c := range cookies {
+		entry := *c
+		if entry.Domain == "" {
+			entry.Domain = u.Hostname()
+		} else {
+			entry.Domain = "." + strings.TrimPrefix(entry.Domain, ".")
+		}
+		if entry.Path == "" || !strings.HasPrefix(entry.Path, "/") {
+			entry.Path = defaultPath(u.Path)
+		}
+		if entry.MaxAge > 0 {
+			entry.Expires = now.Add(time.Duration(entry.MaxAge) * time.Second)
+		}
+
+		key := entry.Domain + ";" + entry.Path + ";" + entry.Name
+		if entry.MaxAge < 0 || (!entry.Expires.IsZero() && entry.Expires.Before(now)) {
+			delete(j.entries, key)
+			continue
+		}
+		j.entries[key] = &entry
+	}
+}
+
+// Cookies implements the http.CookieJar interface.
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// add adds cookies whose domain is already known, eg: cookies loaded from a file or a browser.
+// A domain starting with a dot means the cookie is also sent to subdomains.
+func (j *cookieJar) add(cookies []*http.Cookie) {
+	for _, c := range cookies {
+		domain := strings.TrimPrefix(c.Domain, ".")
+		if domain == "" {
+			continue
+		}
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		cookie := *c
+		if !strings.HasPrefix(c.Domain, ".") {
+			// host-only cookie
+			cookie.Domain = ""
+		}
+		j.SetCookies(&url.URL{Scheme: scheme, Host: domain, Path: c.Path}, []*http.Cookie{&cookie})
+	}
+}
+
+// load reads cookies in Netscape HTTP cookie format.
+func (j *cookieJar) load(r io.Reader) error {
+	cookies, err := parseNetscapeCookies(r)
+	if err != nil {
+		return err
+	}
+	j.add(cookies)
+	return nil
+}
+
+// save writes all unexpired cookies in Netscape HTTP cookie format.
+func (j *cookieJar) save(w io.Writer) error {
+	j.mu.Lock()
+	keys := make([]string, 0, len(j.entries))
+	for key := range j.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	now := time.Now()
+	lines := []string{
+		"# Netscape HTTP Cookie File",
+		"# This file is generated by lux, do not edit.",
+		"",
+	}
+	for _, key := range keys {
+		c := j.entries[key]
+		if !c.Expires.IsZero() && c.Expires.Before(now) {
+			continue
+		}
+		domain := c.Domain
+		if c.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		lines = append(lines, strings.Join([]string{
+			domain,
+			netscapeBool(strings.HasPrefix(c.Domain, ".")),
+			c.Path,
+			netscapeBool(c.Secure),
+			strconv.FormatInt(expires, 10),
+			c.Name,
+			c.Value,
+		}, "\t"))
+	}
+	j.mu.Unlock()
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// parseNetscapeCookies parses cookies in Netscape HTTP cookie format,
+// the domain of the returned cookies starts with a dot if the cookie is also sent to subdomains.
+// It returns an empty list if the text is not in Netscape format.
+func parseNetscapeCookies(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		if httpOnly {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		} else if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			continue
+		}
+		expires, err := strconv.ParseInt(strings.Split(fields[4], ".")[0], 10, 64)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		domain := strings.TrimPrefix(fields[0], ".")
+		if strings.EqualFold(fields[1], "TRUE") {
+			domain = "." + domain
+		}
+		cookie := &http.Cookie{
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		// 0 means a session cookie
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return cookies, nil
+}
+
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// defaultPath returns the directory part of the URL path as defined in RFC 6265 section 5.1.4.
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+// AddCookies adds cookies to the cookie jar of the client,
+// a domain starting with a dot means the cookie is also sent to subdomains.
+func (c *Client) AddCookies(cookies []*http.Cookie) {
+	c.client().jar.add(cookies)
+}
+
+// Cookies returns the cookies in the cookie jar of the client that would be sent to the given URL.
+func (c *Client) Cookies(rawURL string) []*http.Cookie {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	return c.client().jar.Cookies(u)
+}
+
+// LoadCookies loads the cookies from a Netscape cookie file into the cookie jar of the client,
+// a missing file is not an error.
+func (c *Client) LoadCookies(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	defer f.Close() // nolint
+	return c.client().jar.load(f)
+}
+
+// SaveCookies saves the cookies in the cookie jar of the client to a Netscape cookie file.
+func (c *Client) SaveCookies(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = c.client().jar.save(f); err != nil {
+		f.Close() // nolint
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(f.Close())
+}
+
+// AddCookies adds cookies to the cookie jar of the default client.
+func AddCookies(cookies []*http.Cookie) {
+	DefaultClient().AddCookies(cookies)
+}
+
+// Cookies returns the cookies in the cookie jar of the default client that would be sent to the given URL.
+func Cookies(rawURL string) []*http.Cookie {
+	return DefaultClient().Cookies(rawURL)
+}
+
+// LoadCookies loads the cookies from a Netscape cookie file into the cookie jar of the default client.
+func LoadCookies(path string) error {
+	return DefaultClient().LoadCookies(path)
+}
+
+// SaveCookies saves the cookies in the cookie jar of the default client to a Netscape cookie file.
+func SaveCookies(path string) error {
+	return DefaultClient().SaveCookies(path)
+}
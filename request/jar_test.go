@@ -0,0 +1,96 @@
+package request
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCookieJarRoundTrip(t *testing.T) {
+	netscape := strings.Join([]string{
+		"# Netscape HTTP Cookie File",
+		".bilibili.com\tTRUE\t/\tFALSE\t4102444800\tbuvid3\tbuvid-value",
+		"#HttpOnly_.bilibili.com\tTRUE\t/\tTRUE\t4102444800\tSESSDATA\tsessdata-value",
+		"www.douyin.com\tFALSE\t/\tFALSE\t0\tsession\tsession-value",
+		".example.com\tTRUE\t/\tFALSE\t946684800\texpired\texpired-value",
+	}, "\n")
+
+	j := newCookieJar()
+	if err := j.load(strings.NewReader(netscape)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.bilibili.com/x/web-interface/view", want: "SESSDATA=sessdata-value; buvid3=buvid-value"},
+		{url: "http://www.bilibili.com/", want: "buvid3=buvid-value"},
+		{url: "https://www.douyin.com/video/1", want: "session=session-value"},
+		{url: "https://v.douyin.com/", want: ""},
+		{url: "https://www.example.com/", want: ""},
+	}
+	check := func(j *cookieJar) {
+		for _, tt := range tests {
+			u, _ := url.Parse(tt.url)
+			var pairs []string
+			for _, c := range j.Cookies(u) {
+				pairs = append(pairs, c.Name+"="+c.Value)
+			}
+			sort.Strings(pairs)
+			if got := strings.Join(pairs, "; "); got != tt.want {
+				t.Errorf("Cookies(%s) = %q, want %q", tt.url, got, tt.want)
+			}
+		}
+	}
+	check(j)
+
+	var buf bytes.Buffer
+	if err := j.save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "#HttpOnly_.bilibili.com\tTRUE\t/\tTRUE\t4102444800\tSESSDATA\tsessdata-value") {
+		t.Errorf("HttpOnly cookie is not saved:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "expired") {
+		t.Errorf("expired cookie should not be saved:\n%s", buf.String())
+	}
+
+	loaded := newCookieJar()
+	if err := loaded.load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	check(loaded)
+}
+
+func TestSharedCookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/set" {
+			http.SetCookie(w, &http.Cookie{Name: "ttwid", Value: "ttwid-value", Path: "/"})
+			return
+		}
+		c, err := r.Cookie("ttwid")
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(c.Value)) // nolint
+	}))
+	defer server.Close()
+
+	SetOptions(Options{RetryTimes: 1})
+	if _, err := Get(server.URL+"/set", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	body, err := Get(server.URL+"/get", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "ttwid-value" {
+		t.Errorf("got %q, want %q", body, "ttwid-value")
+	}
+}
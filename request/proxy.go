@@ -0,0 +1,113 @@
+package request
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// noProxy is the proxy value that sends requests directly.
+const noProxy = "direct"
+
+type proxyFunc func(*http.Request) (*url.URL, error)
+
+// proxyRule routes the requests of a site through a proxy.
+type proxyRule struct {
+	site  string
+	proxy *url.URL
+}
+
+func (r proxyRule) match(host string) bool {
+	return matchSite(r.site, host)
+}
+
+// matchSite reports whether the host belongs to the site, a site with a dot is a domain (iqiyi.com matches
+// www.iqiyi.com), otherwise it is the name of the site (iqiyi matches www.iqiyi.com and pps.iqiyi.tv).
+func matchSite(site, host string) bool {
+	host = strings.ToLower(host)
+	if strings.Contains(site, ".") {
+		return host == site || strings.HasSuffix(host, "."+site)
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == site {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProxy parses a proxy URL, it returns nil for the "direct" proxy.
+func parseProxy(proxy string) (*url.URL, error) {
+	if proxy == noProxy {
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, errors.Errorf("unsupported proxy %q, the scheme must be one of http, https, socks5 and socks5h", proxy)
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("invalid proxy %q", proxy)
+	}
+	return u, nil
+}
+
+// parseProxyRules parses rules in the form of "site=proxy".
+func parseProxyRules(rules []string) ([]proxyRule, error) {
+	parsed := make([]proxyRule, 0, len(rules))
+	for _, rule := range rules {
+		site, proxy, ok := strings.Cut(rule, "=")
+		site = strings.ToLower(strings.TrimSpace(site))
+		if !ok || site == "" {
+			return nil, errors.Errorf("invalid proxy rule %q, it should be in the form of site=proxy", rule)
+		}
+		u, err := parseProxy(strings.TrimSpace(proxy))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, proxyRule{site: site, proxy: u})
+	}
+	return parsed, nil
+}
+
+// loopback reports whether the host is the local machine, eg: localhost or 127.0.0.1.
+func loopback(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newProxyFunc returns the proxy function of http.Transport, the local machine is always reached directly,
+// then the first matching rule wins, then the given proxy, then the proxy environment variables.
+func newProxyFunc(proxy string, rules []proxyRule) (proxyFunc, error) {
+	fallback := http.ProxyFromEnvironment
+	if proxy != "" {
+		u, err := parseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		fallback = func(*http.Request) (*url.URL, error) {
+			return u, nil
+		}
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if loopback(req.URL.Hostname()) {
+			return nil, nil
+		}
+		for _, rule := range rules {
+			if rule.match(req.URL.Hostname()) {
+				return rule.proxy, nil
+			}
+		}
+		return fallback(req)
+	}, nil
+}
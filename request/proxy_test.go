@@ -0,0 +1,115 @@
+package request
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyRuleMatch(t *testing.T) {
+	tests := []struct {
+		site string
+		host string
+		want bool
+	}{
+		{site: "iqiyi.com", host: "www.iqiyi.com", want: true},
+		{site: "iqiyi.com", host: "iqiyi.com", want: true},
+		{site: "iqiyi.com", host: "notiqiyi.com", want: false},
+		{site: "iqiyi", host: "cache.video.iqiyi.com", want: true},
+		{site: "iqiyi", host: "www.mgtv.com", want: false},
+	}
+	for _, tt := range tests {
+		if got := (proxyRule{site: tt.site}).match(tt.host); got != tt.want {
+			t.Errorf("proxyRule{%s}.match(%s) = %v, want %v", tt.site, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidProxy(t *testing.T) {
+	defer SetOptions(Options{})
+	for _, opt := range []Options{
+		{Proxy: "ftp://127.0.0.1:21"},
+		{Proxy: "socks5://"},
+		{DownloadProxy: "127.0.0.1:1080"},
+		{ProxyRules: []string{"iqiyi"}},
+		{ProxyRules: []string{"=http://127.0.0.1:1087"}},
+	} {
+		if err := SetOptions(opt); err == nil {
+			t.Errorf("SetOptions(%+v) should fail", opt)
+		}
+	}
+}
+
+// newProxy returns an HTTP proxy that answers every request with its name.
+func newProxy(name, auth string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth != "" && r.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)) {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		w.Write([]byte(name + " " + r.Host)) // nolint
+	}))
+}
+
+func TestProxy(t *testing.T) {
+	defaultProxy := newProxy("default", "user:password")
+	defer defaultProxy.Close()
+	downloadProxy := newProxy("download", "")
+	defer downloadProxy.Close()
+	ruleProxy := newProxy("rule", "")
+	defer ruleProxy.Close()
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct " + r.Host)) // nolint
+	}))
+	defer local.Close()
+	defer SetOptions(Options{})
+
+	err := SetOptions(Options{
+		RetryTimes:    1,
+		Proxy:         "http://user:password@" + defaultProxy.Listener.Addr().String(),
+		DownloadProxy: downloadProxy.URL,
+		ProxyRules:    []string{"iqiyi=" + ruleProxy.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		download bool
+		want     string
+	}{
+		{name: "default proxy", url: "http://www.youtube.com/", want: "default www.youtube.com"},
+		{name: "download proxy", url: "http://rr1.googlevideo.com/", download: true, want: "download rr1.googlevideo.com"},
+		{name: "proxy rule", url: "http://www.iqiyi.com/", want: "rule www.iqiyi.com"},
+		{name: "proxy rule in download", url: "http://data.video.iqiyi.com/", download: true, want: "rule data.video.iqiyi.com"},
+		{name: "loopback", url: local.URL, want: "direct " + local.Listener.Addr().String()},
+		{name: "loopback in download", url: local.URL, download: true, want: "direct " + local.Listener.Addr().String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if tt.download {
+				res, err := DownloadRequest(http.MethodGet, tt.url, nil, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Body.Close() // nolint
+				body, _ := io.ReadAll(res.Body)
+				got = string(body)
+			} else {
+				body, err := Get(tt.url, "", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = body
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
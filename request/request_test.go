@@ -1,7 +1,12 @@
 package request
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGet(t *testing.T) {
@@ -50,8 +55,8 @@ func TestGet(t *testing.T) {
 	}
 
 	// with config
-	debug = true
-	rawCookie = "name: value;"
+	SetOptions(Options{Debug: true, Cookie: "name: value;"})
+	defer SetOptions(Options{})
 	_, err = Get("https://google.com", "", nil)
 	if err != nil {
 		t.Error()
@@ -142,3 +147,69 @@ func TestContentType(t *testing.T) {
 		})
 	}
 }
+
+func TestLocation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/absolute":
+			http.Redirect(w, r, "https://www.example.com/video/1", http.StatusFound)
+		case "/relative":
+			http.Redirect(w, r, "/video/2?a=b", http.StatusMovedPermanently)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "absolute", path: "/absolute", want: "https://www.example.com/video/1"},
+		{name: "relative", path: "/relative", want: server.URL + "/video/2?a=b"},
+		{name: "no redirect", path: "/", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Location(server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Location() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok")) // nolint
+	}))
+	defer server.Close()
+
+	client, err := New(Options{RetryTimes: 3, RetryBackoff: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := client.WithContext(ctx)
+
+	if body, err := canceled.Get(server.URL, "", nil); err != nil || body != "ok" {
+		t.Fatalf("Get() = %q, %v before the context is canceled", body, err)
+	}
+	// the retry backoff is interrupted by the cancellation
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err = canceled.Get(server.URL+"/unavailable", "", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get() error = %v, want context.Canceled", err)
+	}
+	if _, err = canceled.Get(server.URL, "", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get() error = %v, want context.Canceled", err)
+	}
+	if body, err := client.Get(server.URL, "", nil); err != nil || body != "ok" {
+		t.Errorf("Get() = %q, %v, the original client should not be canceled", body, err)
+	}
+}
@@ -0,0 +1,235 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRetryBackoff    = time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+	// maxRetryAfter is the longest Retry-After that is waited for, the request fails instead of hanging for hours.
+	maxRetryAfter = 5 * time.Minute
+)
+
+// Errors of the status codes that are never retried, use errors.Is to check them.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+)
+
+// HTTPError is returned when the server responds with a status code >= 400.
+type HTTPError struct {
+	URL        string
+	StatusCode int
+	// RetryAfter is the delay asked by the Retry-After header, 0 if there is none.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s request error: HTTP %d", e.URL, e.StatusCode)
+}
+
+// Is reports whether the status code matches ErrUnauthorized, ErrForbidden or ErrNotFound.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	}
+	return false
+}
+
+// Temporary reports whether the same request may succeed later.
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusRequestTimeout
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+	if d := time.Until(t); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// IsTransient reports whether the error is worth retrying: timeouts, stalls, connection resets,
+// 5xx and 429 are, while errors like 401/403/404, certificate errors and unknown hosts are not.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Temporary()
+	}
+	var stallErr *stallError
+	if errors.As(err, &stallErr) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var (
+		verificationErr *tls.CertificateVerificationError
+		authorityErr    x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		certificateErr  x509.CertificateInvalidError
+		dnsErr          *net.DNSError
+		pathErr         *fs.PathError
+	)
+	switch {
+	case errors.As(err, &verificationErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &certificateErr):
+		return false
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return false
+	case errors.As(err, &pathErr):
+		// reading or writing local files
+		return false
+	}
+
+	// every error of http.Client is a *url.Error, which is a net.Error itself
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// RetryPolicy decides whether and when a failed request is retried.
+type RetryPolicy struct {
+	// Times is the maximum number of attempts.
+	Times int
+	// Backoff is the delay before the first retry, it doubles after each retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Next returns how long to wait before retrying the failed attempt (counting from 0),
+// it returns false if the error is permanent or there are no attempts left.
+// The Retry-After asked by the server takes precedence over the backoff.
+func (p RetryPolicy) Next(attempt int, err error) (time.Duration, bool) {
+	if attempt+1 >= p.Times || !IsTransient(err) {
+		return 0, false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		if httpErr.RetryAfter > maxRetryAfter {
+			return 0, false
+		}
+		return httpErr.RetryAfter, true
+	}
+	return p.backoff(attempt), true
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 0; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	// random jitter, so that the threads of a download don't retry at the same moment
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// retryRule overrides the retry policy of a site.
+type retryRule struct {
+	site    string
+	times   int
+	backoff time.Duration
+}
+
+// parseRetryRules parses rules in the form of "site=times" or "site=times/backoff", eg: bilibili=20/2s.
+func parseRetryRules(rules []string) ([]retryRule, error) {
+	parsed := make([]retryRule, 0, len(rules))
+	for _, rule := range rules {
+		site, value, ok := strings.Cut(rule, "=")
+		site = strings.ToLower(strings.TrimSpace(site))
+		if !ok || site == "" {
+			return nil, errors.Errorf("invalid retry rule %q, it should be in the form of site=times or site=times/backoff", rule)
+		}
+		times, backoff, _ := strings.Cut(strings.TrimSpace(value), "/")
+		r := retryRule{site: site}
+		var err error
+		if r.times, err = strconv.Atoi(times); err != nil || r.times < 1 {
+			return nil, errors.Errorf("invalid retry times in rule %q", rule)
+		}
+		if backoff != "" {
+			if r.backoff, err = time.ParseDuration(backoff); err != nil || r.backoff <= 0 {
+				return nil, errors.Errorf("invalid retry backoff in rule %q", rule)
+			}
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+// RetryPolicy returns the retry policy of the URL, the first matching retry rule overrides the options.
+func (c *Client) RetryPolicy(rawURL string) RetryPolicy {
+	c = c.client()
+	policy := RetryPolicy{
+		Times:      c.opt.RetryTimes,
+		Backoff:    durationOrDefault(c.opt.RetryBackoff, defaultRetryBackoff),
+		MaxBackoff: durationOrDefault(c.opt.RetryMaxBackoff, defaultRetryMaxBackoff),
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		for _, rule := range c.retryRules {
+			if !matchSite(rule.site, u.Hostname()) {
+				continue
+			}
+			policy.Times = rule.times
+			if rule.backoff > 0 {
+				policy.Backoff = rule.backoff
+			}
+			break
+		}
+	}
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = policy.Backoff
+	}
+	return policy
+}
@@ -0,0 +1,180 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    []int
+		wantHits  int32
+		wantErr   error
+		transient bool
+	}{
+		{name: "recover from 5xx", status: []int{503, 502, 200}, wantHits: 3},
+		{name: "recover from 429", status: []int{429, 200}, wantHits: 2},
+		{name: "fail fast on 404", status: []int{404, 200}, wantHits: 1, wantErr: ErrNotFound},
+		{name: "fail fast on 403", status: []int{403, 200}, wantHits: 1, wantErr: ErrForbidden},
+		{name: "fail fast on 401", status: []int{401, 200}, wantHits: 1, wantErr: ErrUnauthorized},
+		{name: "give up", status: []int{500, 500, 500, 500, 500, 200}, wantHits: 4, transient: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&hits, 1)
+				w.WriteHeader(tt.status[n-1])
+			}))
+			defer server.Close()
+
+			c, err := New(Options{RetryTimes: 4, RetryBackoff: time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.Get(server.URL, "", nil)
+			if got := atomic.LoadInt32(&hits); got != tt.wantHits {
+				t.Errorf("got %d requests, want %d", got, tt.wantHits)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if tt.transient && !IsTransient(err) {
+				t.Errorf("error %v should be transient", err)
+			}
+			if tt.wantErr == nil && !tt.transient && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryPostBody(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body) // nolint
+	}))
+	defer server.Close()
+
+	c, err := New(Options{RetryTimes: 2, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Request(http.MethodPost, server.URL, strings.NewReader("payload"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close() // nolint
+	if body, _ := io.ReadAll(res.Body); string(body) != "payload" {
+		t.Errorf("got body %q after retry, want %q", body, "payload")
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	c, err := New(Options{
+		RetryTimes:      3,
+		RetryBackoff:    100 * time.Millisecond,
+		RetryMaxBackoff: 300 * time.Millisecond,
+		RetryRules:      []string{"bilibili=10/1s", "youtube.com=5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url  string
+		want RetryPolicy
+	}{
+		{url: "https://www.iqiyi.com/", want: RetryPolicy{Times: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}},
+		{url: "https://upos-hz-mirrorakam.akamaized.bilibili.com/", want: RetryPolicy{Times: 10, Backoff: time.Second, MaxBackoff: time.Second}},
+		{url: "https://www.youtube.com/", want: RetryPolicy{Times: 5, Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		if got := c.RetryPolicy(tt.url); got != tt.want {
+			t.Errorf("RetryPolicy(%s) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+
+	policy := c.RetryPolicy("https://www.iqiyi.com/")
+	serverError := &HTTPError{StatusCode: http.StatusInternalServerError}
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond} {
+		delay, ok := policy.Next(attempt, serverError)
+		if !ok || delay < max/2 || delay > max {
+			t.Errorf("Next(%d) = %s, %v, want between %s and %s", attempt, delay, ok, max/2, max)
+		}
+	}
+	if _, ok := policy.Next(2, serverError); ok {
+		t.Error("no attempts should be left")
+	}
+	if delay, ok := policy.Next(0, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second}); !ok || delay != 2*time.Second {
+		t.Errorf("Retry-After is not honoured: %s, %v", delay, ok)
+	}
+	if _, ok := policy.Next(0, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}); ok {
+		t.Error("an hour long Retry-After should not be waited for")
+	}
+
+	for _, rule := range []string{"bilibili", "bilibili=0", "bilibili=3/x", "=3"} {
+		if _, err := New(Options{RetryRules: []string{rule}}); err == nil {
+			t.Errorf("retry rule %q should be invalid", rule)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "5xx", err: errors.WithStack(&HTTPError{StatusCode: 503}), want: true},
+		{name: "429", err: &HTTPError{StatusCode: 429}, want: true},
+		{name: "404", err: &HTTPError{StatusCode: 404}, want: false},
+		{name: "stall", err: errors.Wrap(&stallError{received: "data", timeout: time.Second}, "file copy error"), want: true},
+		{name: "reset", err: errors.Wrap(syscall.ECONNRESET, "request error"), want: true},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true},
+		{name: "other", err: errors.New("invalid data"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "120", want: 2 * time.Minute},
+		{value: "-1", want: 0},
+		{value: "soon", want: 0},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), want: 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %s", future, got)
+	}
+}
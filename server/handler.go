@@ -0,0 +1,182 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// keepAliveInterval is how often a comment is sent to the idle event streams, so proxies don't close them.
+const keepAliveInterval = 30 * time.Second
+
+// Handler returns the HTTP handler of the job API:
+//
+//	POST /jobs                    submit a JobRequest, it returns the created jobs
+//	GET  /jobs                    list the jobs
+//	GET  /jobs/{id}               get a job
+//	GET  /jobs/{id}/info          get the extracted data of a job, the same JSON as lux -j
+//	POST /jobs/{id}/cancel        cancel a job and remove its files
+//	POST /jobs/{id}/pause         pause a job
+//	POST /jobs/{id}/resume        resume a paused job
+//	GET  /jobs/{id}/files         list the downloaded files of a job
+//	GET  /jobs/{id}/files/{name}  fetch a downloaded file
+//	GET  /events                  the updates of the jobs as Server-Sent Events, ?job={id} for a single job
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", s.handleSubmit)
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Jobs())
+	})
+	mux.HandleFunc("GET /jobs/{id}", s.handleJob(s.Job))
+	mux.HandleFunc("GET /jobs/{id}/info", s.handleInfo)
+	mux.HandleFunc("POST /jobs/{id}/cancel", s.handleJob(s.Cancel))
+	mux.HandleFunc("POST /jobs/{id}/pause", s.handleJob(s.Pause))
+	mux.HandleFunc("POST /jobs/{id}/resume", s.handleJob(s.Resume))
+	mux.HandleFunc("GET /jobs/{id}/files", s.handleFiles)
+	mux.HandleFunc("GET /jobs/{id}/files/{name}", s.handleFile)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	return mux
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid job request"))
+		return
+	}
+	jobs, err := s.Submit(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, jobs)
+}
+
+// handleJob responds with the job returned by fn.
+func (s *Server) handleJob(fn func(id string) (Job, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := fn(r.PathValue("id"))
+		if err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	}
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	data, err := s.Data(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	if data == nil {
+		writeError(w, http.StatusConflict, errors.New("the job has not been extracted yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	files, err := s.Files(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.Job(id); err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	// os.DirFS rejects the names that leave the directory of the job
+	http.ServeFileFS(w, r, os.DirFS(s.dir(id)), r.PathValue("name"))
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("job")
+	var initial []Job
+	if id != "" {
+		job, err := s.Job(id)
+		if err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		initial = []Job{job}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	updates := s.Subscribe()
+	defer s.Unsubscribe(updates)
+	if id == "" {
+		initial = s.Jobs()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// the current states come first, so the client doesn't miss the updates before it subscribes
+	for _, job := range initial {
+		writeEvent(w, job)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case job, ok := <-updates:
+			if !ok {
+				return
+			}
+			if id != "" && job.ID != id {
+				continue
+			}
+			writeEvent(w, job)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n") // nolint
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes the job as an event named "job".
+func writeEvent(w http.ResponseWriter, job Job) {
+	data, _ := json.Marshal(job)                     // nolint
+	fmt.Fprintf(w, "event: job\ndata: %s\n\n", data) // nolint
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	e.Encode(v) // nolint
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+// State is the state of a job.
+type State string
+
+const (
+	// StateQueued indicates the job is waiting for a worker.
+	StateQueued State = "queued"
+	// StateExtracting indicates the data of the URL is being extracted.
+	StateExtracting State = "extracting"
+	// StateDownloading indicates the streams are being downloaded.
+	StateDownloading State = "downloading"
+	// StatePaused indicates the job is stopped until it is resumed, the downloaded parts are kept.
+	StatePaused State = "paused"
+	// StateCompleted indicates all the items are downloaded.
+	StateCompleted State = "completed"
+	// StateFailed indicates the extraction or a download failed.
+	StateFailed State = "failed"
+	// StateCanceled indicates the job is canceled and its files are removed.
+	StateCanceled State = "canceled"
+)
+
+// done reports whether the job will never run again.
+func (s State) done() bool {
+	return s == StateCompleted || s == StateFailed || s == StateCanceled
+}
+
+// JobRequest is the body of a request to submit jobs, a job is created for each URL.
+type JobRequest struct {
+	URL  string   `json:"url,omitempty"`
+	URLs []string `json:"urls,omitempty"`
+
+	// Playlist, Items, ItemStart, ItemEnd and OldestFirst select the items of a playlist, see extractors.Options.
+	Playlist    bool   `json:"playlist,omitempty"`
+	Items       string `json:"items,omitempty"`
+	ItemStart   int    `json:"item_start,omitempty"`
+	ItemEnd     int    `json:"item_end,omitempty"`
+	OldestFirst bool   `json:"oldest_first,omitempty"`
+
+	// Stream is the ID of the stream to download, the best one is downloaded if it is empty.
+	Stream     string `json:"stream,omitempty"`
+	AudioOnly  bool   `json:"audio_only,omitempty"`
+	Caption    bool   `json:"caption,omitempty"`
+	OutputName string `json:"output_name,omitempty"`
+}
+
+// Job is the status of a submitted URL.
+type Job struct {
+	ID      string     `json:"id"`
+	URL     string     `json:"url"`
+	Request JobRequest `json:"request"`
+	State   State      `json:"state"`
+	Error   string     `json:"error,omitempty"`
+	// Items is the number of the extracted items, Item is the index of the item being downloaded.
+	Items int `json:"items"`
+	Item  int `json:"item"`
+	// Downloaded and Total are the bytes of the stream being downloaded.
+	Downloaded int64     `json:"downloaded"`
+	Total      int64     `json:"total"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// job is a Job with the state of its run, it is guarded by the lock of the Server.
+type job struct {
+	Job
+	data []*extractors.Data
+	// cancel stops the running job, it is nil if the job is not running
+	cancel context.CancelFunc
+	// created is true once the job has created its directory, only that directory is removed
+	created bool
+}
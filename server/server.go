@@ -0,0 +1,481 @@
+// Package server runs lux as an HTTP service, the submitted URLs are extracted and downloaded by a pool of workers.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/downloader"
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
+)
+
+// Options defines the options of the Server.
+type Options struct {
+	// Workers is the number of jobs that run at the same time, 1 if it is not positive.
+	Workers int
+	// OutputPath is the directory of the downloaded files, the files of each job are saved in a sub-directory named by the job ID.
+	OutputPath string
+	// SiteOptions returns the base of the extraction and download options of the URL of a job, eg: the credentials,
+	// the thread number and the options of the site of the URL, nil means the zero options.
+	SiteOptions func(url string) (extractors.Options, downloader.Options, error)
+	// Client sends the requests of the jobs, nil means the default client.
+	Client *request.Client
+}
+
+var (
+	// ErrNotFound means there is no such job.
+	ErrNotFound = errors.New("job not found")
+	// ErrConflict means the job can not be changed in its state.
+	ErrConflict = errors.New("the job can not be changed in its state")
+)
+
+// Server queues the jobs and runs them with a bounded number of workers.
+type Server struct {
+	option Options
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	jobs   map[string]*job
+	order  []*job
+	queue  []*job
+	closed bool
+	// subscribers receive the updates of the jobs
+	subscribers map[chan Job]struct{}
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// New returns a Server and starts its workers.
+func New(option Options) *Server {
+	if option.Workers <= 0 {
+		option.Workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		option:      option,
+		jobs:        make(map[string]*job),
+		subscribers: make(map[chan Job]struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	s.cond = sync.NewCond(&s.lock)
+	for i := 0; i < option.Workers; i++ {
+		s.workers.Add(1)
+		go s.work()
+	}
+	return s
+}
+
+// Close stops the running jobs and the workers, the downloaded parts are kept, and closes the channels of the subscribers.
+func (s *Server) Close() {
+	s.lock.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	for ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, ch)
+	}
+	s.lock.Unlock()
+	s.cancel()
+	s.workers.Wait()
+}
+
+// Submit creates a job for each URL of the request.
+func (s *Server) Submit(req JobRequest) ([]Job, error) {
+	urls := req.URLs
+	if req.URL != "" {
+		urls = append([]string{req.URL}, urls...)
+	}
+	if len(urls) == 0 {
+		return nil, errors.New("no URL to download")
+	}
+	req.URL, req.URLs = "", nil
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil, errors.New("the server is closed")
+	}
+	jobs := make([]Job, 0, len(urls))
+	now := time.Now()
+	for _, u := range urls {
+		id, err := s.newID()
+		if err != nil {
+			return nil, err
+		}
+		j := &job{Job: Job{
+			ID:        id,
+			URL:       strings.TrimSpace(u),
+			Request:   req,
+			State:     StateQueued,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}}
+		s.jobs[j.ID] = j
+		s.order = append(s.order, j)
+		s.queue = append(s.queue, j)
+		s.cond.Signal()
+		s.publish(j)
+		jobs = append(jobs, j.Job)
+	}
+	return jobs, nil
+}
+
+// Jobs returns all the jobs in the order they are submitted.
+func (s *Server) Jobs() []Job {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	jobs := make([]Job, len(s.order))
+	for i, j := range s.order {
+		jobs[i] = j.Job
+	}
+	return jobs
+}
+
+// Job returns the job of the ID.
+func (s *Server) Job(id string) (Job, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return j.Job, nil
+}
+
+// Data returns the extracted data of the job, it is nil if the job has not been extracted yet.
+func (s *Server) Data(id string) ([]*extractors.Data, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return j.data, nil
+}
+
+// Pause stops a queued or running job, it keeps the downloaded parts, so the download continues once it is resumed.
+func (s *Server) Pause(id string) (Job, error) {
+	return s.stop(id, StatePaused)
+}
+
+// Cancel stops a job that has not finished and removes its files.
+func (s *Server) Cancel(id string) (Job, error) {
+	return s.stop(id, StateCanceled)
+}
+
+func (s *Server) stop(id string, state State) (Job, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if j.State.done() || (state == StatePaused && j.State == StatePaused) {
+		return j.Job, ErrConflict
+	}
+	s.setState(j, state)
+	if j.cancel != nil {
+		// the worker finishes the job once the requests are canceled
+		j.cancel()
+	} else if state == StateCanceled {
+		s.remove(j)
+	}
+	return j.Job, nil
+}
+
+// Resume queues a paused job again.
+func (s *Server) Resume(id string) (Job, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if j.State != StatePaused {
+		return j.Job, ErrConflict
+	}
+	s.setState(j, StateQueued)
+	if j.cancel == nil {
+		// a running job is queued by its worker once it stops
+		s.queue = append(s.queue, j)
+		s.cond.Signal()
+	}
+	return j.Job, nil
+}
+
+// Files returns the names of the downloaded files of the job.
+func (s *Server) Files(id string) ([]string, error) {
+	s.lock.Lock()
+	j, ok := s.jobs[id]
+	created := ok && j.created
+	s.lock.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !created {
+		return []string{}, nil
+	}
+	entries, err := os.ReadDir(s.dir(j.ID))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), downloader.DOWNLOAD_FILE_EXT) {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
+
+// newID returns a random job ID, the IDs stay unique across the runs that share the output directory.
+func (s *Server) newID() (string, error) {
+	for {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", errors.WithStack(err)
+		}
+		id := hex.EncodeToString(b)
+		if _, ok := s.jobs[id]; !ok {
+			return id, nil
+		}
+	}
+}
+
+// dir returns the directory of the files of the job.
+func (s *Server) dir(id string) string {
+	return filepath.Join(s.option.OutputPath, id)
+}
+
+// mkdir creates the directory of the job, a directory that exists and was not created by the job is not reused,
+// its files may belong to the job of an earlier run.
+func (s *Server) mkdir(j *job) (string, error) {
+	dir := s.dir(j.ID)
+	s.lock.Lock()
+	created := j.created
+	s.lock.Unlock()
+	if created {
+		return dir, errors.WithStack(os.MkdirAll(dir, 0755))
+	}
+	if err := os.MkdirAll(s.option.OutputPath, 0755); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		if os.IsExist(err) {
+			return "", errors.Errorf("the directory %s of the job already exists", dir)
+		}
+		return "", errors.WithStack(err)
+	}
+	s.lock.Lock()
+	j.created = true
+	s.lock.Unlock()
+	return dir, nil
+}
+
+// remove removes the directory of the job if the job created it, it is called with the lock held.
+func (s *Server) remove(j *job) {
+	if j.created {
+		os.RemoveAll(s.dir(j.ID)) // nolint
+	}
+}
+
+// Subscribe returns a channel of the updates of the jobs, it must be released by Unsubscribe, it is closed once the Server is closed.
+// The updates are dropped while the channel is full, so a slow subscriber never blocks the jobs.
+func (s *Server) Subscribe() chan Job {
+	ch := make(chan Job, 64)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		close(ch)
+		return ch
+	}
+	s.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe stops sending the updates to the channel.
+func (s *Server) Unsubscribe(ch chan Job) {
+	s.lock.Lock()
+	delete(s.subscribers, ch)
+	s.lock.Unlock()
+}
+
+// setState changes the state of the job and publishes it, the lock must be held.
+func (s *Server) setState(j *job, state State) {
+	j.State = state
+	j.UpdatedAt = time.Now()
+	s.publish(j)
+}
+
+// publish sends the job to the subscribers, the lock must be held.
+func (s *Server) publish(j *job) {
+	for ch := range s.subscribers {
+		select {
+		case ch <- j.Job:
+		default:
+		}
+	}
+}
+
+func (s *Server) work() {
+	defer s.workers.Done()
+	for {
+		s.lock.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.lock.Unlock()
+			return
+		}
+		j := s.queue[0]
+		s.queue = s.queue[1:]
+		if j.State != StateQueued {
+			// paused or canceled while it is queued
+			s.lock.Unlock()
+			continue
+		}
+		ctx, cancel := context.WithCancel(s.ctx)
+		j.cancel = cancel
+		j.Error = ""
+		s.setState(j, StateExtracting)
+		s.lock.Unlock()
+
+		err := s.run(ctx, j)
+		cancel()
+		s.finish(j, err)
+	}
+}
+
+// finish sets the final state of the job once its worker stops.
+func (s *Server) finish(j *job, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j.cancel = nil
+	switch j.State {
+	case StateCanceled:
+		s.remove(j)
+	case StatePaused:
+	case StateQueued:
+		// resumed before the worker stops
+		s.queue = append(s.queue, j)
+		s.cond.Signal()
+	default:
+		if err != nil {
+			j.Error = err.Error()
+			if s.closed {
+				// the server is shutting down, the job could be resumed by another run
+				s.setState(j, StatePaused)
+				return
+			}
+			s.setState(j, StateFailed)
+			return
+		}
+		s.setState(j, StateCompleted)
+	}
+}
+
+// siteOptions returns the base of the options of the URL.
+func (s *Server) siteOptions(url string) (extractors.Options, downloader.Options, error) {
+	if s.option.SiteOptions == nil {
+		return extractors.Options{}, downloader.Options{}, nil
+	}
+	return s.option.SiteOptions(url)
+}
+
+// run extracts the URL of the job if it has not been extracted, and downloads all the items.
+func (s *Server) run(ctx context.Context, j *job) error {
+	extractOption, downloadOption, err := s.siteOptions(j.URL)
+	if err != nil {
+		return err
+	}
+	// the site may have a client of its own
+	client := s.option.Client
+	if extractOption.Client != nil {
+		client = extractOption.Client
+	}
+	client = client.WithContext(ctx)
+
+	s.lock.Lock()
+	data, req := j.data, j.Request
+	s.lock.Unlock()
+	if data == nil {
+		option := extractOption
+		option.Client = client
+		option.Playlist = req.Playlist
+		option.Items = req.Items
+		option.ItemStart = req.ItemStart
+		option.ItemEnd = req.ItemEnd
+		option.OldestFirst = option.OldestFirst || req.OldestFirst
+		if data, err = extractors.Extract(j.URL, option); err != nil {
+			return err
+		}
+	}
+
+	s.lock.Lock()
+	j.data = data
+	j.Items = len(data)
+	s.setState(j, StateDownloading)
+	s.lock.Unlock()
+
+	dir, err := s.mkdir(j)
+	if err != nil {
+		return err
+	}
+	option := downloadOption
+	option.Client = client
+	option.Silent = true
+	option.InfoOnly = false
+	option.OutputPath = dir
+	if req.Stream != "" {
+		option.Stream = req.Stream
+	}
+	option.AudioOnly = option.AudioOnly || req.AudioOnly
+	option.Caption = option.Caption || req.Caption
+	if req.OutputName != "" {
+		option.OutputName = req.OutputName
+	}
+	option.Progress = func(current, total int64) {
+		s.lock.Lock()
+		j.Downloaded, j.Total = current, total
+		j.UpdatedAt = time.Now()
+		s.publish(j)
+		s.lock.Unlock()
+	}
+
+	var errs []string
+	for i, d := range data {
+		s.lock.Lock()
+		j.Item, j.Downloaded, j.Total = i, 0, 0
+		s.publish(j)
+		s.lock.Unlock()
+
+		err := d.Err
+		if err == nil {
+			err = downloader.New(option).Download(d)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iawia002/lux/extractors"
+	_ "github.com/iawia002/lux/extractors/universal"
+)
+
+var video = bytes.Repeat([]byte("lux"), 100000)
+
+// mediaServer serves the video, /slow.mp4 stops in the middle of the first download until release is closed.
+func mediaServer(t *testing.T, release chan struct{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		if r.URL.Path == "/slow.mp4" && r.Header.Get("Range") == "" {
+			select {
+			case <-release:
+			default:
+				w.Header().Set("Content-Length", "300000")
+				w.Write(video[:len(video)/2]) // nolint
+				w.(http.Flusher).Flush()
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+				return
+			}
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(video))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newServer(t *testing.T) (*Server, *httptest.Server) {
+	s := New(Options{Workers: 2, OutputPath: t.TempDir()})
+	api := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		s.Close()
+		api.Close()
+	})
+	return s, api
+}
+
+func waitFor(t *testing.T, s *Server, id string, check func(Job) bool) Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := s.Job(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if check(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for job %s: %+v", id, job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func inState(state State) func(Job) bool {
+	return func(job Job) bool {
+		return job.State == state
+	}
+}
+
+func call(t *testing.T, method, url, body string, want int, v interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close() // nolint
+	data, _ := io.ReadAll(res.Body)
+	if res.StatusCode != want {
+		t.Fatalf("%s %s = %d %s, want %d", method, url, res.StatusCode, data, want)
+	}
+	if v != nil {
+		if err = json.Unmarshal(data, v); err != nil {
+			t.Fatalf("%s %s: %v: %s", method, url, err, data)
+		}
+	}
+}
+
+func TestJob(t *testing.T) {
+	media := mediaServer(t, nil)
+	s, api := newServer(t)
+
+	var jobs []Job
+	call(t, http.MethodPost, api.URL+"/jobs", `{"url": "`+media.URL+`/video.mp4"}`, http.StatusCreated, &jobs)
+	if len(jobs) != 1 || jobs[0].State != StateQueued {
+		t.Fatalf("POST /jobs = %+v", jobs)
+	}
+	id := jobs[0].ID
+	job := waitFor(t, s, id, func(job Job) bool { return job.State.done() })
+	if job.State != StateCompleted || job.Downloaded != int64(len(video)) || job.Total != int64(len(video)) {
+		t.Fatalf("job = %+v, want completed", job)
+	}
+
+	var data []*extractors.Data
+	call(t, http.MethodGet, api.URL+"/jobs/"+id+"/info", "", http.StatusOK, &data)
+	if len(data) != 1 || data[0].Type != extractors.DataTypeVideo || data[0].Title != "video" {
+		t.Errorf("GET /jobs/%s/info = %+v", id, data)
+	}
+	var files []string
+	call(t, http.MethodGet, api.URL+"/jobs/"+id+"/files", "", http.StatusOK, &files)
+	if len(files) != 1 || files[0] != "video.mp4" {
+		t.Fatalf("GET /jobs/%s/files = %v", id, files)
+	}
+	res, err := http.Get(api.URL + "/jobs/" + id + "/files/video.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close() // nolint
+	if !bytes.Equal(body, video) {
+		t.Errorf("GET /jobs/%s/files/video.mp4 returns %d bytes, want %d", id, len(body), len(video))
+	}
+
+	call(t, http.MethodGet, api.URL+"/jobs/"+id+"/files/..%2F..%2Fetc%2Fpasswd", "", http.StatusBadRequest, nil)
+	call(t, http.MethodPost, api.URL+"/jobs/"+id+"/pause", "", http.StatusConflict, nil)
+	call(t, http.MethodGet, api.URL+"/jobs/404", "", http.StatusNotFound, nil)
+	call(t, http.MethodPost, api.URL+"/jobs", `{}`, http.StatusBadRequest, nil)
+	call(t, http.MethodGet, api.URL+"/jobs", "", http.StatusOK, &jobs)
+	if len(jobs) != 1 {
+		t.Errorf("GET /jobs = %+v", jobs)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	release := make(chan struct{})
+	media := mediaServer(t, release)
+	s, api := newServer(t)
+
+	var jobs []Job
+	call(t, http.MethodPost, api.URL+"/jobs", `{"url": "`+media.URL+`/slow.mp4"}`, http.StatusCreated, &jobs)
+	id := jobs[0].ID
+	waitFor(t, s, id, func(job Job) bool { return job.Downloaded > 0 })
+
+	call(t, http.MethodPost, api.URL+"/jobs/"+id+"/pause", "", http.StatusOK, nil)
+	waitFor(t, s, id, inState(StatePaused))
+	close(release)
+	call(t, http.MethodPost, api.URL+"/jobs/"+id+"/resume", "", http.StatusOK, nil)
+	waitFor(t, s, id, inState(StateCompleted))
+
+	// the download continues from the downloaded part
+	data, err := os.ReadFile(filepath.Join(s.option.OutputPath, id, "slow.mp4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, video) {
+		t.Errorf("the resumed file has %d bytes, want %d", len(data), len(video))
+	}
+}
+
+func TestCancel(t *testing.T) {
+	media := mediaServer(t, make(chan struct{}))
+	s, api := newServer(t)
+
+	var jobs []Job
+	call(t, http.MethodPost, api.URL+"/jobs", `{"url": "`+media.URL+`/slow.mp4"}`, http.StatusCreated, &jobs)
+	id := jobs[0].ID
+	waitFor(t, s, id, func(job Job) bool { return job.Downloaded > 0 })
+
+	call(t, http.MethodPost, api.URL+"/jobs/"+id+"/cancel", "", http.StatusOK, nil)
+	waitFor(t, s, id, inState(StateCanceled))
+	if _, err := os.Stat(filepath.Join(s.option.OutputPath, id)); !os.IsNotExist(err) {
+		t.Errorf("the files of the canceled job should be removed: %v", err)
+	}
+	call(t, http.MethodPost, api.URL+"/jobs/"+id+"/resume", "", http.StatusConflict, nil)
+}
+
+// TestRestart runs a second server on the output directory of the first one, it must keep the files of the first run.
+func TestRestart(t *testing.T) {
+	media := mediaServer(t, nil)
+	dir := t.TempDir()
+	first := New(Options{OutputPath: dir})
+	jobs, err := first.Submit(JobRequest{URL: media.URL + "/video.mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, first, jobs[0].ID, inState(StateCompleted))
+	first.Close()
+
+	second := New(Options{OutputPath: dir})
+	defer second.Close()
+	again, err := second.Submit(JobRequest{URL: media.URL + "/slow.mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].ID == jobs[0].ID {
+		t.Fatalf("the job ID %s of the first run is used again", again[0].ID)
+	}
+	waitFor(t, second, again[0].ID, inState(StateDownloading))
+	if _, err = second.Cancel(again[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, second, again[0].ID, inState(StateCanceled))
+
+	// a job never takes over the directory of an earlier run
+	j := &job{Job: Job{ID: jobs[0].ID}}
+	if _, err = second.mkdir(j); err == nil {
+		t.Error("the directory of a job of the first run is reused")
+	}
+	second.lock.Lock()
+	second.remove(j)
+	second.lock.Unlock()
+	if files, _ := os.ReadDir(filepath.Join(dir, jobs[0].ID)); len(files) != 1 {
+		t.Errorf("the files of the first run are removed: %v", files)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	media := mediaServer(t, nil)
+	s, api := newServer(t)
+
+	jobs, err := s.Submit(JobRequest{URLs: []string{media.URL + "/video.mp4"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Get(api.URL + "/events?job=" + jobs[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close() // nolint
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %s", ct)
+	}
+
+	var states []State
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var job Job
+		if err = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &job); err != nil {
+			t.Fatal(err)
+		}
+		if job.ID != jobs[0].ID {
+			t.Fatalf("event of job %s, want %s", job.ID, jobs[0].ID)
+		}
+		if len(states) == 0 || states[len(states)-1] != job.State {
+			states = append(states, job.State)
+		}
+		if job.State.done() {
+			break
+		}
+	}
+	if states[len(states)-1] != StateCompleted {
+		t.Errorf("states = %v, want completed at last", states)
+	}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the extractor tests")
+
+// masked replaces the volatile values in the golden files.
+const masked = "<masked>"
+
+// maxDiffCells limits the size of the line diff, larger files only show the first difference.
+const maxDiffCells = 4000000
+
+// CheckGolden compares the full extracted data with the golden file testdata/golden/<test name>.json of the package,
+// run the tests with -update to write the golden files.
+// masks are the dot separated paths of the volatile values in each Data that are not compared, eg: signed URLs,
+// * matches any map key or list index, eg: "streams.*.parts.*.url".
+func CheckGolden(t testing.TB, data []*extractors.Data, masks ...string) {
+	t.Helper()
+	got, err := normalize(data, masks)
+	if err != nil {
+		t.Fatalf("Unexpected error:\n%+v\n", err)
+	}
+	path := filepath.Join("testdata", "golden", t.Name()+".json")
+	if *update {
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run the test with -update to create it", err)
+	}
+	if string(want) != got {
+		t.Errorf("the data differs from %s (-want +got), run the test with -update if it is expected:\n%s", path, diff(string(want), got))
+	}
+}
+
+// normalize returns the indented JSON of the data with the masked values replaced,
+// the map keys are sorted so the output is stable.
+func normalize(data []*extractors.Data, masks []string) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	var items []interface{}
+	if err = json.Unmarshal(raw, &items); err != nil {
+		return "", err
+	}
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// an error has no exported fields, compare its message instead
+		delete(m, "err")
+		if data[i].Err != nil {
+			m["err"] = data[i].Err.Error()
+		}
+		for _, path := range masks {
+			items[i] = mask(items[i], strings.Split(path, "."))
+		}
+	}
+	var out strings.Builder
+	encoder := json.NewEncoder(&out)
+	// keep the & in URLs readable
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(items); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// mask replaces the values at the path.
+func mask(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return masked
+	}
+	key, rest := path[0], path[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if key == "*" || key == k {
+				v[k] = mask(child, rest)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if key == "*" || key == strconv.Itoa(i) {
+				v[i] = mask(child, rest)
+			}
+		}
+	}
+	return value
+}
+
+// diff returns the changed lines between want and got with a few lines of context.
+func diff(want, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+	if len(a)*len(b) > maxDiffCells {
+		i := 0
+		for i < len(a) && i < len(b) && a[i] == b[i] {
+			i++
+		}
+		var lines []string
+		if i < len(a) {
+			lines = append(lines, fmt.Sprintf("-%d: %s", i+1, a[i]))
+		}
+		if i < len(b) {
+			lines = append(lines, fmt.Sprintf("+%d: %s", i+1, b[i]))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	const context = 2
+	var out []string
+	last := -1
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		start := max(k-context, last+1)
+		if last >= 0 && start > last+1 {
+			out = append(out, "...")
+		}
+		for c := start; c < k; c++ {
+			out = append(out, " "+lines[c].text)
+		}
+		out = append(out, string(l.op)+l.text)
+		last = k
+		// trailing context, stops at the next change
+		for c := k + 1; c < len(lines) && c <= k+context && lines[c].op == ' '; c++ {
+			out = append(out, " "+lines[c].text)
+			last = c
+		}
+	}
+	return strings.Join(out, "\n")
+}
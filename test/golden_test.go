@@ -0,0 +1,122 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+// fakeT records the failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+	name   string
+	errors []string
+}
+
+func (f *fakeT) Helper()      {}
+func (f *fakeT) Name() string { return f.name }
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.Errorf(format, args...)
+}
+
+func (f *fakeT) Fatal(args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func goldenData(url string) []*extractors.Data {
+	return []*extractors.Data{
+		{
+			URL:   "https://www.bilibili.com/video/av20203945",
+			Site:  "哔哩哔哩 bilibili.com",
+			Title: "【2018拜年祭单品】相遇day by day",
+			Type:  extractors.DataTypeVideo,
+			Streams: map[string]*extractors.Stream{
+				"80-7": {
+					ID:      "80-7",
+					Quality: "高清 1080P",
+					Parts: []*extractors.Part{
+						{URL: url, Size: 100, Ext: "mp4"},
+						{URL: url + "&audio", Size: 10, Ext: "m4a"},
+					},
+					Size:    110,
+					Ext:     "mp4",
+					NeedMux: true,
+				},
+			},
+		},
+		extractors.EmptyData("https://www.bilibili.com/video/av1", errors.New("video not found")),
+	}
+}
+
+func TestCheckGolden(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	*update = true
+	CheckGolden(t, goldenData("https://upos-sz.bilivideo.com/1.m4s?deadline=1"), "streams.*.parts.*.url")
+	*update = false
+	golden, err := os.ReadFile(filepath.Join("testdata", "golden", t.Name()+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"url": "<masked>"`, `"err": "video not found"`, `"NeedMux": true`} {
+		if !strings.Contains(string(golden), s) {
+			t.Errorf("golden file should contain %s:\n%s", s, golden)
+		}
+	}
+
+	// the signed URL is masked
+	CheckGolden(t, goldenData("https://upos-sz.bilivideo.com/1.m4s?deadline=2"), "streams.*.parts.*.url")
+
+	f := &fakeT{TB: t, name: t.Name()}
+	data := goldenData("https://upos-sz.bilivideo.com/1.m4s?deadline=2")
+	data[0].Streams["80-7"].NeedMux = false
+	CheckGolden(f, data, "streams.*.parts.*.url")
+	if len(f.errors) != 1 || !strings.Contains(f.errors[0], "-        \"NeedMux\": true,\n+        \"NeedMux\": false,") {
+		t.Errorf("unexpected failures: %q", f.errors)
+	}
+
+	f = &fakeT{TB: t, name: "TestMissing"}
+	CheckGolden(f, data)
+	if len(f.errors) == 0 || !strings.Contains(f.errors[0], "-update to create it") {
+		t.Errorf("unexpected failures: %q", f.errors)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{
+			name: "changed line",
+			want: "a\nb\nc\nd\ne\nf\ng",
+			got:  "a\nb\nc\nD\ne\nf\ng",
+			diff: " b\n c\n-d\n+D\n e\n f",
+		},
+		{
+			name: "separate changes",
+			want: "1\n2\n3\n4\n5\n6\n7\n8\n9",
+			got:  "0\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+			diff: "-1\n+0\n 2\n 3\n...\n 8\n 9\n+10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(tt.want, tt.got); got != tt.diff {
+				t.Errorf("diff() =\n%s\nwant\n%s", got, tt.diff)
+			}
+		})
+	}
+}
@@ -2,12 +2,20 @@ package test
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 
 	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
 )
 
+// CassetteEnv is the environment variable that selects where the requests of the extractor tests go:
+// "record" sends them to the sites and records the cassettes again, "replay" only replays the cassettes
+// and fails the tests without one. By default a cassette is replayed if it exists, otherwise the sites are requested.
+const CassetteEnv = "LUX_CASSETTE"
+
 // Args Arguments for extractor tests
 type Args struct {
 	URL     string
@@ -16,6 +24,44 @@ type Args struct {
 	Size    int64
 }
 
+// Client returns the request client of an extractor test, the requests are recorded in or replayed from
+// testdata/cassettes/<test name>.json of the package, see CassetteEnv.
+func Client(t *testing.T) *request.Client {
+	t.Helper()
+	path := filepath.Join("testdata", "cassettes", t.Name()+".json")
+	mode := request.ModeReplay
+	switch os.Getenv(CassetteEnv) {
+	case "record":
+		mode = request.ModeRecord
+	case "replay":
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("no cassette %s, record it with %s=record", path, CassetteEnv)
+		}
+	default:
+		if _, err := os.Stat(path); err != nil {
+			// no cassette yet, use the default client
+			return nil
+		}
+	}
+
+	recorder, err := request.NewRecorder(path, mode, request.Transport())
+	CheckError(t, err)
+	client, err := request.New(request.Options{
+		RetryTimes: 3,
+		Transport:  recorder,
+	})
+	CheckError(t, err)
+	if mode == request.ModeRecord {
+		t.Cleanup(func() {
+			// a failed test would record broken responses
+			if !t.Failed() {
+				CheckError(t, recorder.Save())
+			}
+		})
+	}
+	return client
+}
+
 // CheckData check the given data
 func CheckData(args, data Args) bool {
 	if args.Title != data.Title {
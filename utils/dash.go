@@ -0,0 +1,384 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/request"
+)
+
+// DashRepresentation is a representation of a DASH manifest, a video or an audio track of the first period.
+type DashRepresentation struct {
+	ID string
+	// MimeType is the type of the track, eg: "video/mp4", "audio/mp4".
+	MimeType  string
+	Codecs    string
+	Bandwidth int64
+	Width     int
+	Height    int
+	// Segments are the initialization segment, without a duration, and the media segments of the track,
+	// their data is the track once joined in order. A track of one file has one segment without a duration.
+	Segments []Segment
+}
+
+type mpd struct {
+	Type                      string      `xml:"type,attr"`
+	MediaPresentationDuration string      `xml:"mediaPresentationDuration,attr"`
+	BaseURL                   string      `xml:"BaseURL"`
+	Periods                   []mpdPeriod `xml:"Period"`
+}
+
+type mpdPeriod struct {
+	Duration        string              `xml:"duration,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	AdaptationSets  []mpdAdaptationSet  `xml:"AdaptationSet"`
+}
+
+type mpdAdaptationSet struct {
+	MimeType        string              `xml:"mimeType,attr"`
+	ContentType     string              `xml:"contentType,attr"`
+	Codecs          string              `xml:"codecs,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+	Representations []mpdRepresentation `xml:"Representation"`
+}
+
+type mpdRepresentation struct {
+	ID              string              `xml:"id,attr"`
+	MimeType        string              `xml:"mimeType,attr"`
+	Codecs          string              `xml:"codecs,attr"`
+	Bandwidth       int64               `xml:"bandwidth,attr"`
+	Width           int                 `xml:"width,attr"`
+	Height          int                 `xml:"height,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+}
+
+type mpdSegmentTemplate struct {
+	Initialization string `xml:"initialization,attr"`
+	Media          string `xml:"media,attr"`
+	StartNumber    *int64 `xml:"startNumber,attr"`
+	Timescale      int64  `xml:"timescale,attr"`
+	Duration       int64  `xml:"duration,attr"`
+	Timeline       []struct {
+		T *int64 `xml:"t,attr"`
+		D int64  `xml:"d,attr"`
+		R int64  `xml:"r,attr"`
+	} `xml:"SegmentTimeline>S"`
+}
+
+type mpdSegmentList struct {
+	Timescale      int64 `xml:"timescale,attr"`
+	Duration       int64 `xml:"duration,attr"`
+	Initialization struct {
+		SourceURL string `xml:"sourceURL,attr"`
+	} `xml:"Initialization"`
+	SegmentURLs []struct {
+		Media string `xml:"media,attr"`
+	} `xml:"SegmentURL"`
+}
+
+// inherit returns the template with the attributes that it leaves out taken from the template of the parent element.
+func (t *mpdSegmentTemplate) inherit(parent *mpdSegmentTemplate) *mpdSegmentTemplate {
+	if t == nil {
+		return parent
+	}
+	if parent == nil {
+		return t
+	}
+	merged := *t
+	if merged.Initialization == "" {
+		merged.Initialization = parent.Initialization
+	}
+	if merged.Media == "" {
+		merged.Media = parent.Media
+	}
+	if merged.StartNumber == nil {
+		merged.StartNumber = parent.StartNumber
+	}
+	if merged.Timescale == 0 {
+		merged.Timescale = parent.Timescale
+	}
+	if merged.Duration == 0 {
+		merged.Duration = parent.Duration
+	}
+	if len(merged.Timeline) == 0 {
+		merged.Timeline = parent.Timeline
+	}
+	return &merged
+}
+
+var (
+	isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:([\d.]+)S)?)?$`)
+	templateIDPattern  = regexp.MustCompile(`\$(RepresentationID|Number|Time|Bandwidth)(%0\d+d)?\$`)
+)
+
+// parseISODuration parses the durations of the manifest, eg: "PT1H2M3.5S", into seconds.
+func parseISODuration(s string) (float64, error) {
+	m := isoDurationPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, errors.Errorf("invalid duration %q", s)
+	}
+	var seconds float64
+	for i, unit := range []float64{86400, 3600, 60, 1} {
+		if m[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid duration %q", s)
+		}
+		seconds += v * unit
+	}
+	return seconds, nil
+}
+
+// fillTemplate replaces the identifiers of a SegmentTemplate URL, eg: "$RepresentationID$/$Number%05d$.m4s".
+func fillTemplate(template string, rep mpdRepresentation, number, time int64) string {
+	// $$ is an escaped $
+	parts := strings.Split(template, "$$")
+	for i, part := range parts {
+		parts[i] = templateIDPattern.ReplaceAllStringFunc(part, func(id string) string {
+			m := templateIDPattern.FindStringSubmatch(id)
+			format := m[2]
+			if format == "" {
+				format = "%d"
+			}
+			switch m[1] {
+			case "RepresentationID":
+				return rep.ID
+			case "Number":
+				return fmt.Sprintf(format, number)
+			case "Time":
+				return fmt.Sprintf(format, time)
+			default:
+				return fmt.Sprintf(format, rep.Bandwidth)
+			}
+		})
+	}
+	return strings.Join(parts, "$")
+}
+
+// resolve returns the reference resolved against the base URL, an empty reference is the base itself.
+func resolve(base *url.URL, ref string) (*url.URL, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return base, nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return base.ResolveReference(u), nil
+}
+
+// templateSegments returns the segments of a SegmentTemplate, duration is the duration of the period in seconds.
+func templateSegments(t *mpdSegmentTemplate, rep mpdRepresentation, base *url.URL, duration float64) ([]Segment, error) {
+	timescale := t.Timescale
+	if timescale == 0 {
+		timescale = 1
+	}
+	number := int64(1)
+	if t.StartNumber != nil {
+		number = *t.StartNumber
+	}
+
+	var segments []Segment
+	add := func(ref string, d int64) error {
+		u, err := resolve(base, ref)
+		if err != nil {
+			return err
+		}
+		segments = append(segments, Segment{URL: u.String(), Duration: float64(d) / float64(timescale)})
+		return nil
+	}
+	if t.Initialization != "" {
+		if err := add(fillTemplate(t.Initialization, rep, 0, 0), 0); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case len(t.Timeline) > 0:
+		var time int64
+		for i, s := range t.Timeline {
+			if s.T != nil {
+				time = *s.T
+			}
+			repeat := s.R
+			if repeat < 0 {
+				// repeated until the next S or the end of the period
+				end := int64(math.Ceil(duration * float64(timescale)))
+				if i+1 < len(t.Timeline) && t.Timeline[i+1].T != nil {
+					end = *t.Timeline[i+1].T
+				}
+				if s.D <= 0 || end <= time {
+					return nil, errors.New("unable to get the segments of the timeline")
+				}
+				repeat = (end-time+s.D-1)/s.D - 1
+			}
+			for j := int64(0); j <= repeat; j++ {
+				if err := add(fillTemplate(t.Media, rep, number, time), s.D); err != nil {
+					return nil, err
+				}
+				number++
+				time += s.D
+			}
+		}
+	case t.Duration > 0:
+		if duration <= 0 {
+			return nil, errors.New("unable to get the number of segments without the duration of the video")
+		}
+		count := int64(math.Ceil(duration * float64(timescale) / float64(t.Duration)))
+		for i := int64(0); i < count; i++ {
+			d := t.Duration
+			// the last segment ends with the period
+			if i == count-1 {
+				d = int64(math.Round(duration*float64(timescale))) - i*t.Duration
+			}
+			if err := add(fillTemplate(t.Media, rep, number+i, i*t.Duration), d); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.Errorf("no segments in the template of the representation %s", rep.ID)
+	}
+	return segments, nil
+}
+
+// listSegments returns the segments of a SegmentList.
+func listSegments(l *mpdSegmentList, base *url.URL) ([]Segment, error) {
+	timescale := l.Timescale
+	if timescale == 0 {
+		timescale = 1
+	}
+	var segments []Segment
+	if l.Initialization.SourceURL != "" {
+		u, err := resolve(base, l.Initialization.SourceURL)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, Segment{URL: u.String()})
+	}
+	for _, s := range l.SegmentURLs {
+		u, err := resolve(base, s.Media)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, Segment{URL: u.String(), Duration: float64(l.Duration) / float64(timescale)})
+	}
+	return segments, nil
+}
+
+// ParseDash parses the representations of the first period of a static DASH manifest, uri is the URL of the
+// manifest that the relative URLs are resolved against.
+func ParseDash(manifest []byte, uri string) ([]DashRepresentation, error) {
+	var m mpd
+	if err := xml.Unmarshal(manifest, &m); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if m.Type == "dynamic" {
+		return nil, errors.New("live DASH streams are not supported")
+	}
+	if len(m.Periods) == 0 {
+		return nil, errors.New("no periods in the DASH manifest")
+	}
+	period := m.Periods[0]
+
+	duration := period.Duration
+	if duration == "" {
+		duration = m.MediaPresentationDuration
+	}
+	var seconds float64
+	if duration != "" {
+		var err error
+		if seconds, err = parseISODuration(duration); err != nil {
+			return nil, err
+		}
+	}
+
+	base, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, ref := range []string{m.BaseURL, period.BaseURL} {
+		if base, err = resolve(base, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	var reps []DashRepresentation
+	for _, set := range period.AdaptationSets {
+		setBase, err := resolve(base, set.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range set.Representations {
+			repBase, err := resolve(setBase, r.BaseURL)
+			if err != nil {
+				return nil, err
+			}
+			rep := DashRepresentation{
+				ID:        r.ID,
+				MimeType:  firstNonEmpty(r.MimeType, set.MimeType),
+				Codecs:    firstNonEmpty(r.Codecs, set.Codecs),
+				Bandwidth: r.Bandwidth,
+				Width:     r.Width,
+				Height:    r.Height,
+			}
+			if rep.MimeType == "" && set.ContentType != "" {
+				rep.MimeType = set.ContentType + "/mp4"
+			}
+
+			template := r.SegmentTemplate.inherit(set.SegmentTemplate.inherit(period.SegmentTemplate))
+			list := r.SegmentList
+			if list == nil {
+				list = set.SegmentList
+			}
+			switch {
+			case template != nil:
+				rep.Segments, err = templateSegments(template, r, repBase, seconds)
+			case list != nil:
+				rep.Segments, err = listSegments(list, repBase)
+			case repBase.String() != uri:
+				// the track is one file, the SegmentBase only indexes it
+				rep.Segments = []Segment{{URL: repBase.String()}}
+			default:
+				err = errors.Errorf("no segments in the representation %s", r.ID)
+			}
+			if err != nil {
+				return nil, err
+			}
+			reps = append(reps, rep)
+		}
+	}
+	return reps, nil
+}
+
+// DashRepresentations gets the representations of the DASH manifest of the url.
+func DashRepresentations(client *request.Client, uri string) ([]DashRepresentation, error) {
+	manifest, err := client.Get(uri, "", nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ParseDash([]byte(manifest), uri)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDash(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="static" mediaPresentationDuration="PT9.5S">
+  <Period>
+    <AdaptationSet mimeType="video/mp4">
+      <SegmentTemplate initialization="$RepresentationID$/init.mp4" media="$RepresentationID$/$Number%03d$.m4s" startNumber="0" timescale="1000" duration="4000"/>
+      <Representation id="720p" bandwidth="2000000" width="1280" height="720" codecs="avc1.64001f"/>
+    </AdaptationSet>
+    <AdaptationSet contentType="audio">
+      <Representation id="audio" bandwidth="128000">
+        <SegmentTemplate initialization="a/init.mp4" media="a/$Time$.m4s" timescale="48000">
+          <SegmentTimeline>
+            <S t="0" d="192000" r="1"/>
+            <S d="72000"/>
+          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet mimeType="audio/mp4">
+      <Representation id="file" bandwidth="64000">
+        <BaseURL>https://cdn.example.com/audio.m4a</BaseURL>
+        <SegmentBase indexRange="0-100"/>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+	got, err := ParseDash([]byte(manifest), "https://example.com/video/manifest.mpd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DashRepresentation{
+		{
+			ID: "720p", MimeType: "video/mp4", Codecs: "avc1.64001f", Bandwidth: 2000000, Width: 1280, Height: 720,
+			Segments: []Segment{
+				{URL: "https://example.com/video/720p/init.mp4"},
+				{URL: "https://example.com/video/720p/000.m4s", Duration: 4},
+				{URL: "https://example.com/video/720p/001.m4s", Duration: 4},
+				{URL: "https://example.com/video/720p/002.m4s", Duration: 1.5},
+			},
+		},
+		{
+			ID: "audio", MimeType: "audio/mp4", Bandwidth: 128000,
+			Segments: []Segment{
+				{URL: "https://example.com/video/a/init.mp4"},
+				{URL: "https://example.com/video/a/0.m4s", Duration: 4},
+				{URL: "https://example.com/video/a/192000.m4s", Duration: 4},
+				{URL: "https://example.com/video/a/384000.m4s", Duration: 1.5},
+			},
+		},
+		{
+			ID: "file", MimeType: "audio/mp4", Bandwidth: 64000,
+			Segments: []Segment{{URL: "https://cdn.example.com/audio.m4a"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDash() = %+v, want %+v", got, want)
+	}
+
+	if _, err = ParseDash([]byte(`<MPD type="dynamic"><Period/></MPD>`), "https://example.com/live.mpd"); err == nil {
+		t.Error("ParseDash() of a live manifest should fail")
+	}
+}
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "PT1H2M3.5S", want: 3723.5},
+		{in: "PT634.566S", want: 634.566},
+		{in: "P1DT1S", want: 86401},
+		{in: "1:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseISODuration(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseISODuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseISODuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -155,3 +155,31 @@ func EmbedSubtitles(videoPath string, subtitlePaths []string, langs []string) er
 	}
 	return os.Rename(tempOutput, videoPath)
 }
+
+// EmbedChapters writes the chapters of the FFMETADATA file into the video, the other metadata is kept.
+func EmbedChapters(videoPath string, metadataPath string) error {
+	ext := filepath.Ext(videoPath)
+	tempOutput := videoPath + ".temp" + ext
+
+	cmd := exec.Command(
+		findFFmpegExecutable(), "-y", "-i", videoPath, "-i", metadataPath,
+		"-map", "0", "-map_metadata", "0", "-map_chapters", "1", "-c", "copy", tempOutput,
+	)
+	if err := runMergeCmd(cmd, []string{videoPath, metadataPath}, ""); err != nil {
+		return err
+	}
+	return os.Rename(tempOutput, videoPath)
+}
+
+// CutFile copies the part of the video from start to end, in seconds, into a new file without re-encoding,
+// end <= 0 means the end of the video. The cut starts at the nearest keyframe before start.
+func CutFile(videoPath string, outputPath string, start, end float64) error {
+	cmds := []string{"-y", "-ss", fmt.Sprintf("%.3f", start), "-i", videoPath}
+	if end > 0 {
+		cmds = append(cmds, "-t", fmt.Sprintf("%.3f", end-start))
+	}
+	cmds = append(cmds, "-map", "0", "-map_chapters", "-1", "-c", "copy", outputPath)
+
+	// the video is kept, there is nothing to remove
+	return runMergeCmd(exec.Command(findFFmpegExecutable(), cmds...), nil, "")
+}
@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -236,7 +237,7 @@ func ParseInputFile(r io.Reader, items string, itemStart, itemEnd int) []string
 // GetNameAndExt return the name and ext of the URL
 // https://img9.bcyimg.com/drawer/15294/post/1799t/1f5a87801a0711e898b12b640777720f.jpg ->
 // 1f5a87801a0711e898b12b640777720f, jpg
-func GetNameAndExt(uri string) (string, string, error) {
+func GetNameAndExt(client *request.Client, uri string) (string, string, error) {
 	u, err := url.ParseRequestURI(uri)
 	if err != nil {
 		return "", "", err
@@ -249,7 +250,7 @@ func GetNameAndExt(uri string) (string, string, error) {
 	// Image url like this
 	// https://img9.bcyimg.com/drawer/15294/post/1799t/1f5a87801a0711e898b12b640777720f.jpg/w650
 	// has no suffix
-	contentType, err := request.ContentType(uri, uri)
+	contentType, err := client.ContentType(uri, uri)
 	if err != nil {
 		return "", "", err
 	}
@@ -263,23 +264,51 @@ func Md5(text string) string {
 	return fmt.Sprintf("%x", sign.Sum(nil))
 }
 
+// Segment is a media segment of an HLS playlist or a DASH manifest, Duration is in seconds, it is 0 for
+// the variants of a master playlist and the initialization segments.
+type Segment struct {
+	URL      string
+	Duration float64
+}
+
 // M3u8URLs get all urls from m3u8 url
-func M3u8URLs(uri string) ([]string, error) {
+func M3u8URLs(client *request.Client, uri string) ([]string, error) {
+	segments, err := M3u8Segments(client, uri)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(segments))
+	for _, s := range segments {
+		urls = append(urls, s.URL)
+	}
+	return urls, nil
+}
+
+// M3u8Segments gets all the segments of the m3u8 url with their durations.
+func M3u8Segments(client *request.Client, uri string) ([]Segment, error) {
 	if len(uri) == 0 {
 		return nil, errors.New("url is null")
 	}
 
-	html, err := request.Get(uri, "", nil)
+	html, err := client.Get(uri, "", nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	lines := strings.Split(html, "\n")
-	var urls []string
+	var (
+		segments []Segment
+		duration float64
+	)
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#EXTINF:") {
+			// #EXTINF:<duration>,[<title>]
+			duration, _ = strconv.ParseFloat(strings.SplitN(strings.TrimPrefix(line, "#EXTINF:"), ",", 2)[0], 64)
+			continue
+		}
 		if line != "" && !strings.HasPrefix(line, "#") {
 			if strings.HasPrefix(line, "http") {
-				urls = append(urls, line)
+				segments = append(segments, Segment{URL: line, Duration: duration})
 			} else {
 				base, err := url.Parse(uri)
 				if err != nil {
@@ -289,11 +318,12 @@ func M3u8URLs(uri string) ([]string, error) {
 				if err != nil {
 					continue
 				}
-				urls = append(urls, base.ResolveReference(u).String())
+				segments = append(segments, Segment{URL: base.ResolveReference(u).String(), Duration: duration})
 			}
+			duration = 0
 		}
 	}
-	return urls, nil
+	return segments, nil
 }
 
 // Reverse Reverse a string
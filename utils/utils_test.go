@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"testing"
+
+	"github.com/iawia002/lux/request"
 )
 
 func TestMatchOneOf(t *testing.T) {
@@ -310,7 +314,7 @@ func TestGetNameAndExt(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1, _ := GetNameAndExt(tt.args.uri)
+			got, got1, _ := GetNameAndExt(nil, tt.args.uri)
 			if got != tt.want {
 				t.Errorf("GetNameAndExt() got = %v, want %v", got, tt.want)
 			}
@@ -322,7 +326,7 @@ func TestGetNameAndExt(t *testing.T) {
 
 	// error test
 	for _, u := range []string{"https://a.com/a", "test"} {
-		_, _, err := GetNameAndExt(u)
+		_, _, err := GetNameAndExt(nil, u)
 		if err == nil {
 			t.Error()
 		}
@@ -563,3 +567,23 @@ func TestConvertXMLToSRT(t *testing.T) {
 		})
 	}
 }
+
+func TestM3u8Segments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("#EXTM3U\n#EXT-X-TARGETDURATION:10\n#EXTINF:9.5,\nseg0.ts\n#EXTINF:10,title\n" + // nolint
+			"http://cdn.example.com/seg1.ts\n#EXT-X-ENDLIST\n"))
+	}))
+	defer server.Close()
+
+	got, err := M3u8Segments(request.DefaultClient(), server.URL+"/video/index.m3u8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Segment{
+		{URL: server.URL + "/video/seg0.ts", Duration: 9.5},
+		{URL: "http://cdn.example.com/seg1.ts", Duration: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("M3u8Segments() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package watch
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+// Archive records the downloaded items in a file, one key per line, so they are not downloaded again.
+type Archive struct {
+	path  string
+	lock  sync.Mutex
+	items map[string]struct{}
+}
+
+// OpenArchive reads the archive file, it is created once the first item is added.
+func OpenArchive(path string) (*Archive, error) {
+	a := &Archive{
+		path:  path,
+		items: make(map[string]struct{}),
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close() // nolint
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key := strings.TrimSpace(scanner.Text()); key != "" {
+			a.items[key] = struct{}{}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "read archive %s", path)
+	}
+	return a, nil
+}
+
+// Has reports whether the key is in the archive.
+func (a *Archive) Has(key string) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	_, ok := a.items[key]
+	return ok
+}
+
+// Add appends the key to the archive file.
+func (a *Archive) Add(key string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, ok := a.items[key]; ok {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	file, err := os.OpenFile(a.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err = file.WriteString(key + "\n"); err != nil {
+		file.Close() // nolint
+		return errors.WithStack(err)
+	}
+	if err = file.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	a.items[key] = struct{}{}
+	return nil
+}
+
+// Key returns the archive key of the item, eg: "youtube dQw4w9WgXcQ", it falls back to the URL and title
+// if the extractor doesn't set the ID.
+func Key(site string, data *extractors.Data) string {
+	if data.ID != "" {
+		return site + " " + data.ID
+	}
+	return site + " " + data.URL + " " + data.Title
+}
+
+// URLKey returns the archive key of the item of a playlist with the URL, it lets the items downloaded before be
+// skipped before they are extracted.
+func URLKey(site, url string) string {
+	return site + " " + url
+}
@@ -0,0 +1,134 @@
+package watch
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/extractors"
+)
+
+// File is the subscriptions file:
+//
+//	interval = "1h"
+//
+//	[[subscriptions]]
+//	name = "lux"
+//	url = "https://www.youtube.com/playlist?list=PLxxx"
+//	output_path = "/data/videos/lux"
+//	output_name = "{{.Title}}"
+//	match_title = "(?i)tutorial"
+type File struct {
+	// Interval is the default interval of the subscriptions.
+	Interval      time.Duration  `toml:"interval"`
+	Subscriptions []Subscription `toml:"subscriptions"`
+}
+
+// Subscription is a channel or playlist URL that is checked for new items.
+type Subscription struct {
+	// Name is shown in the output, it is the URL if it is empty.
+	Name string `toml:"name"`
+	URL  string `toml:"url"`
+	// Interval overrides the interval of the file.
+	Interval time.Duration `toml:"interval"`
+
+	// OutputPath, OutputName and StreamFormat override the flags, OutputName can be a template of the Data.
+	OutputPath   string `toml:"output_path"`
+	OutputName   string `toml:"output_name"`
+	StreamFormat string `toml:"stream_format"`
+	AudioOnly    bool   `toml:"audio_only"`
+	Caption      bool   `toml:"caption"`
+
+	// Items selects the items of the playlist to check, eg: "1-20" for the latest ones of most channels.
+	Items string `toml:"items"`
+	// MatchTitle and RejectTitle are regular expressions, only the items whose titles match MatchTitle
+	// and don't match RejectTitle are downloaded.
+	MatchTitle  string `toml:"match_title"`
+	RejectTitle string `toml:"reject_title"`
+
+	matchTitle  *regexp.Regexp
+	rejectTitle *regexp.Regexp
+}
+
+// String returns the name of the subscription.
+func (s *Subscription) String() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.URL
+}
+
+// accept reports whether the item passes the filters of the subscription.
+func (s *Subscription) accept(data *extractors.Data) bool {
+	if s.matchTitle != nil && !s.matchTitle.MatchString(data.Title) {
+		return false
+	}
+	if s.rejectTitle != nil && s.rejectTitle.MatchString(data.Title) {
+		return false
+	}
+	return true
+}
+
+// DefaultPath returns the path of the subscriptions file, it is $XDG_CONFIG_HOME/lux/subscriptions.toml on Linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "lux", "subscriptions.toml")
+}
+
+// Load reads the subscriptions file of the path.
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close() // nolint
+
+	file, err := Parse(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid subscriptions file %s", path)
+	}
+	return file, nil
+}
+
+// Parse parses the subscriptions file in TOML format.
+func Parse(r io.Reader) (*File, error) {
+	file := &File{}
+	md, err := toml.NewDecoder(r).Decode(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return nil, errors.Errorf("unknown options: %s", strings.Join(keys, ", "))
+	}
+	for i := range file.Subscriptions {
+		s := &file.Subscriptions[i]
+		s.URL = strings.TrimSpace(s.URL)
+		if s.URL == "" {
+			return nil, errors.Errorf("subscription %d has no url", i+1)
+		}
+		if s.MatchTitle != "" {
+			if s.matchTitle, err = regexp.Compile(s.MatchTitle); err != nil {
+				return nil, errors.Wrapf(err, "invalid match_title of %s", s)
+			}
+		}
+		if s.RejectTitle != "" {
+			if s.rejectTitle, err = regexp.Compile(s.RejectTitle); err != nil {
+				return nil, errors.Wrapf(err, "invalid reject_title of %s", s)
+			}
+		}
+	}
+	return file, nil
+}
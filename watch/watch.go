@@ -0,0 +1,218 @@
+// Package watch checks the subscribed channels and playlists on their intervals, and downloads the new items.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+
+	"github.com/iawia002/lux/downloader"
+	"github.com/iawia002/lux/extractors"
+	"github.com/iawia002/lux/request"
+)
+
+// DefaultInterval is the interval of the subscriptions if neither the subscription nor the options set one.
+const DefaultInterval = time.Hour
+
+// Options defines the options of the Watcher.
+type Options struct {
+	// Interval is the interval of the subscriptions that don't set their own.
+	Interval time.Duration
+	// SiteOptions returns the base of the extraction and download options of the URL of a subscription, eg: the
+	// credentials and the options of the site of the URL, nil means the zero options. The subscriptions override
+	// the output and stream options.
+	SiteOptions func(url string) (extractors.Options, downloader.Options, error)
+	// Client sends the requests, nil means the default client.
+	Client *request.Client
+}
+
+// Watcher downloads the new items of the subscriptions, the downloaded items are recorded in the archive.
+type Watcher struct {
+	option        Options
+	subscriptions []Subscription
+	archive       *Archive
+}
+
+// New returns a Watcher of the subscriptions.
+func New(subscriptions []Subscription, archive *Archive, option Options) *Watcher {
+	return &Watcher{
+		option:        option,
+		subscriptions: subscriptions,
+		archive:       archive,
+	}
+}
+
+// interval returns the interval of the subscription.
+func (w *Watcher) interval(s *Subscription) time.Duration {
+	switch {
+	case s.Interval > 0:
+		return s.Interval
+	case w.option.Interval > 0:
+		return w.option.Interval
+	}
+	return DefaultInterval
+}
+
+// Run checks the subscriptions on their intervals until ctx is done, the errors of a check are printed,
+// and the subscription is checked again on its next interval.
+func (w *Watcher) Run(ctx context.Context) error {
+	if len(w.subscriptions) == 0 {
+		return errors.New("no subscriptions")
+	}
+	next := make([]time.Time, len(w.subscriptions))
+	for {
+		for i := range w.subscriptions {
+			s := &w.subscriptions[i]
+			if time.Now().Before(next[i]) {
+				continue
+			}
+			if _, err := w.Check(ctx, s); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(color.Output, "Checking %s error:\n%+v\n", color.CyanString("%s", s), err) // nolint
+			}
+			next[i] = time.Now().Add(w.interval(s))
+		}
+
+		earliest := next[0]
+		for _, t := range next[1:] {
+			if t.Before(earliest) {
+				earliest = t
+			}
+		}
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// siteOptions returns the base of the options of the URL.
+func (w *Watcher) siteOptions(url string) (extractors.Options, downloader.Options, error) {
+	if w.option.SiteOptions == nil {
+		return extractors.Options{}, downloader.Options{}, nil
+	}
+	return w.option.SiteOptions(url)
+}
+
+// Check downloads the items of the subscription that pass its filters and are not in the archive,
+// it returns the number of the downloaded items.
+func (w *Watcher) Check(ctx context.Context, s *Subscription) (int, error) {
+	extractOption, downloadOption, err := w.siteOptions(s.URL)
+	if err != nil {
+		return 0, err
+	}
+	// the site may have a client of its own
+	client := w.option.Client
+	if extractOption.Client != nil {
+		client = extractOption.Client
+	}
+	client = client.WithContext(ctx)
+	silent := downloadOption.Silent
+	if !silent {
+		fmt.Fprintf(color.Output, "Checking %s\n", color.CyanString("%s", s)) // nolint
+	}
+
+	m, err := extractors.Lookup(s.URL)
+	if err != nil {
+		return 0, err
+	}
+	// the site name keeps the keys of the sites apart, the universal extractor has no name
+	site := m.Site
+	if site == "" {
+		site = "universal"
+	}
+
+	extractOption.Client = client
+	extractOption.Playlist = true
+	extractOption.Items = s.Items
+	extractOption.SkipItem = func(url string) bool {
+		return w.archive.Has(URLKey(site, url))
+	}
+	data, err := extractors.Extract(s.URL, extractOption)
+	if err != nil {
+		return 0, err
+	}
+
+	downloadOption.Client = client
+	downloadOption.InfoOnly = false
+	if s.OutputPath != "" {
+		downloadOption.OutputPath = s.OutputPath
+	}
+	if s.OutputName != "" {
+		downloadOption.OutputName = s.OutputName
+	}
+	if s.StreamFormat != "" {
+		downloadOption.Stream = s.StreamFormat
+	}
+	downloadOption.AudioOnly = downloadOption.AudioOnly || s.AudioOnly
+	downloadOption.Caption = downloadOption.Caption || s.Caption
+	d := downloader.New(downloadOption)
+
+	var (
+		downloaded int
+		errs       []string
+		// the items of a page share its URL, eg: the videos found by the universal extractor
+		urls = make(map[string]int, len(data))
+	)
+	for _, item := range data {
+		urls[item.URL]++
+	}
+	for _, item := range data {
+		if item.Err != nil {
+			// the item is checked again next time
+			errs = append(errs, item.Err.Error())
+			continue
+		}
+		key := Key(site, item)
+		if w.archive.Has(key) {
+			// the archives written before the URL keys skip the item next time
+			if err = w.addURLKey(site, item, urls[item.URL] > 1); err != nil {
+				return downloaded, err
+			}
+			continue
+		}
+		if !s.accept(item) {
+			continue
+		}
+		if err = d.Download(item); err != nil {
+			if ctx.Err() != nil {
+				return downloaded, ctx.Err()
+			}
+			errs = append(errs, fmt.Sprintf("%s: %v", item.Title, err))
+			continue
+		}
+		if err = w.archive.Add(key); err != nil {
+			return downloaded, err
+		}
+		if err = w.addURLKey(site, item, urls[item.URL] > 1); err != nil {
+			return downloaded, err
+		}
+		downloaded++
+	}
+	if !silent {
+		fmt.Fprintf(color.Output, "%s: %d new items\n", color.CyanString("%s", s), downloaded) // nolint
+	}
+	if len(errs) > 0 {
+		return downloaded, errors.New(strings.Join(errs, "\n"))
+	}
+	return downloaded, nil
+}
+
+// addURLKey adds the URL key of the item to the archive, so the item is skipped before it is extracted.
+// A URL shared by several items is not added, it would skip the other items too.
+func (w *Watcher) addURLKey(site string, item *extractors.Data, shared bool) error {
+	key := URLKey(site, item.URL)
+	if item.URL == "" || shared || key == Key(site, item) || w.archive.Has(key) {
+		return nil
+	}
+	return w.archive.Add(key)
+}
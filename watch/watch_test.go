@@ -0,0 +1,188 @@
+package watch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iawia002/lux/downloader"
+	"github.com/iawia002/lux/extractors"
+	_ "github.com/iawia002/lux/extractors/universal"
+)
+
+// channel serves a page with a video for each of the titles, the videos are counted by their downloads and
+// the probes of the extraction.
+func channel(t *testing.T, titles *[]string, downloads, probes *atomic.Int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/channel" {
+			w.Header().Set("Content-Type", "text/html")
+			var b strings.Builder
+			b.WriteString("<html><head><title>channel</title></head><body>")
+			for _, title := range *titles {
+				b.WriteString(`<video src="/` + title + `.mp4"></video>`)
+			}
+			b.WriteString("</body></html>")
+			w.Write([]byte(b.String())) // nolint
+			return
+		}
+		if r.Header.Get("Range") == "" {
+			downloads.Add(1)
+		} else {
+			probes.Add(1)
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(r.URL.Path))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheck(t *testing.T) {
+	titles := []string{"a", "b"}
+	var downloads, probes atomic.Int32
+	server := channel(t, &titles, &downloads, &probes)
+	dir := t.TempDir()
+
+	file, err := Parse(strings.NewReader(`
+[[subscriptions]]
+name = "channel"
+url = "` + server.URL + `/channel"
+output_name = "{{.Title}} ({{.Site}})"
+reject_title = " 3$"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := OpenArchive(filepath.Join(dir, "archive.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := New(file.Subscriptions, archive, Options{
+		SiteOptions: func(string) (extractors.Options, downloader.Options, error) {
+			return extractors.Options{}, downloader.Options{Silent: true, OutputPath: dir}, nil
+		},
+	})
+	s := &w.subscriptions[0]
+
+	if n, err := w.Check(context.Background(), s); err != nil || n != 2 {
+		t.Fatalf("Check() = %d, %v, want 2 new items", n, err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "channel 1 (Universal).mp4")); err != nil {
+		t.Errorf("the output name template is not applied: %v", err)
+	}
+	// the archive is read again, so the items are skipped by a new run
+	if archive, err = OpenArchive(filepath.Join(dir, "archive.txt")); err != nil {
+		t.Fatal(err)
+	}
+	w.archive = archive
+	titles = append(titles, "c", "d")
+	probed := probes.Load()
+	if n, err := w.Check(context.Background(), s); err != nil || n != 1 {
+		t.Fatalf("Check() = %d, %v, want 1 new item, the third one is rejected", n, err)
+	}
+	if got := downloads.Load(); got != 3 {
+		t.Errorf("%d files are downloaded, want 3", got)
+	}
+	// only the new items are extracted
+	if got := probes.Load() - probed; got != probed {
+		t.Errorf("%d probes for the new items, want %d, the archived items are extracted again", got, probed)
+	}
+}
+
+// TestCheckPage checks the items of one page, they share the URL of the page, a failed item is downloaded next time.
+func TestCheckPage(t *testing.T) {
+	var broken atomic.Bool
+	broken.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body><video src="/a.mp4"></video><video src="/b.mp4"></video></body></html>`)) // nolint
+			return
+		}
+		if r.URL.Path == "/b.mp4" && r.Header.Get("Range") == "" && broken.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(r.URL.Path))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	archive, err := OpenArchive(filepath.Join(dir, "archive.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := New([]Subscription{{URL: server.URL + "/page"}}, archive, Options{
+		SiteOptions: func(string) (extractors.Options, downloader.Options, error) {
+			return extractors.Options{}, downloader.Options{Silent: true, OutputPath: dir}, nil
+		},
+	})
+	s := &w.subscriptions[0]
+
+	if n, err := w.Check(context.Background(), s); err == nil || n != 1 {
+		t.Fatalf("Check() = %d, %v, want 1 new item and the error of the other one", n, err)
+	}
+	if archive.Has(URLKey("universal", server.URL+"/page")) {
+		t.Error("the URL of the page is archived, it skips all its items")
+	}
+	broken.Store(false)
+	if n, err := w.Check(context.Background(), s); err != nil || n != 1 {
+		t.Fatalf("Check() = %d, %v, want the failed item", n, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	titles := []string{"a"}
+	var downloads, probes atomic.Int32
+	server := channel(t, &titles, &downloads, &probes)
+	dir := t.TempDir()
+
+	archive, err := OpenArchive(filepath.Join(dir, "archive.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := New([]Subscription{{URL: server.URL + "/channel", Interval: 20 * time.Millisecond}}, archive, Options{
+		SiteOptions: func(string) (extractors.Options, downloader.Options, error) {
+			return extractors.Options{}, downloader.Options{Silent: true, OutputPath: dir}, nil
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err = w.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("%d files are downloaded, want 1", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "valid", content: "interval = \"30m\"\n[[subscriptions]]\nurl = \"https://example.com/c\"\ninterval = \"2h\""},
+		{name: "no url", content: "[[subscriptions]]\nname = \"a\"", wantErr: true},
+		{name: "unknown key", content: "[[subscriptions]]\nurl = \"https://example.com/c\"\noutput = \"a\"", wantErr: true},
+		{name: "invalid filter", content: "[[subscriptions]]\nurl = \"https://example.com/c\"\nmatch_title = \"(\"", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (file.Interval != 30*time.Minute || file.Subscriptions[0].Interval != 2*time.Hour) {
+				t.Errorf("Parse() = %+v", file)
+			}
+		})
+	}
+}